@@ -2,11 +2,14 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"maps"
+	"slices"
 	"strings"
+	"time"
 
-	"github.com/unicrons/aws-root-manager/internal/aws"
 	"github.com/unicrons/aws-root-manager/internal/cli/output"
 	"github.com/unicrons/aws-root-manager/internal/cli/ui"
 	"github.com/unicrons/aws-root-manager/rootmanager"
@@ -23,18 +26,47 @@ func Audit(newRM func(context.Context) (rootmanager.RootManager, error)) *cobra.
 		RunE: func(cmd *cobra.Command, args []string) error {
 			slog.Debug("audit called")
 
-			ctx := context.Background()
+			ctx, cancel := commandContext()
+			defer cancel()
+
 			rm, err := newRM(ctx)
 			if err != nil {
 				slog.Error("failed to initialize root manager", "error", err)
 				return err
 			}
 
-			awscfg, err := aws.LoadAWSConfig(ctx)
+			var exceptions []rootmanager.Exception
+			if exceptionsFlag != "" {
+				exceptions, err = rootmanager.LoadExceptions(exceptionsFlag)
+				if err != nil {
+					return err
+				}
+			}
+			evaluateFindings := exceptionsFlag != "" || failOnFindingsFlag
+
+			var breakGlass []rootmanager.BreakGlassAccount
+			if breakGlassFlag != "" {
+				breakGlass, err = rootmanager.LoadBreakGlassRegistry(breakGlassFlag)
+				if err != nil {
+					return err
+				}
+			}
+			selection, err := accountSelection(accountsFlags)
+			if err != nil {
+				return err
+			}
+			if len(selection.Accounts) == 0 && len(selection.FromFile) == 0 && len(selection.OUs) == 0 && len(selection.Tags) == 0 && breakGlassFlag != "" {
+				// without --accounts, --accounts-file, --ou or --tag, check the break-glass accounts only
+				for _, account := range breakGlass {
+					selection.Accounts = append(selection.Accounts, account.AccountId)
+				}
+			}
+
+			org, orgAccounts, err := organizationAccounts(ctx)
 			if err != nil {
-				return fmt.Errorf("failed to load aws config: %w", err)
+				return err
 			}
-			auditAccounts, err := ui.SelectTargetAccounts(ctx, aws.NewOrganizationsClient(awscfg), accountsFlags)
+			auditAccounts, err := ui.SelectTargetAccounts(ctx, org, orgAccounts, selection)
 			if err != nil {
 				slog.Error("failed to get accounts to audit", "error", err)
 				return err
@@ -45,37 +77,266 @@ func Audit(newRM func(context.Context) (rootmanager.RootManager, error)) *cobra.
 			}
 			slog.Debug("selected accounts", "accounts", strings.Join(auditAccounts, ", "))
 
-			audit, err := rm.AuditAccounts(ctx, auditAccounts)
+			// accounts that are not active cannot be audited but are still reported
+			inactive := inactiveAccounts(orgAccounts, auditAccounts)
+			auditAccounts = slices.DeleteFunc(auditAccounts, func(accountId string) bool {
+				_, ok := inactive[accountId]
+				return ok
+			})
+
+			progressCtx, stopProgress := withProgress(ctx)
+			audit, err := rm.AuditAccounts(progressCtx, auditAccounts)
+			stopProgress()
 			if err != nil {
 				slog.Error("failed to audit accounts", "error", err)
 				return err
 			}
 
-			var skipped int
+			var skipped, partial, cancelled, notAuditable, openFindings, nonCompliant int
 			headers := []string{"Account", "LoginProfile", "AccessKeys", "MFA Devices", "Signing Certificates"}
+			if evaluateFindings {
+				headers = append(headers, "Findings")
+			}
+			if breakGlassFlag != "" {
+				headers = append(headers, "Break-glass")
+			}
+			now := time.Now()
 			var data [][]any
 			for i, acc := range audit {
+				if acc.Cancelled {
+					cancelled++
+					row := []any{auditAccounts[i], "cancelled", "cancelled", "cancelled", "cancelled"}
+					for range len(headers) - len(row) {
+						row = append(row, "cancelled")
+					}
+					data = append(data, row)
+					continue
+				}
 				if acc.Error != "" {
 					skipped++
 					slog.Error("audit failed for account", "account_id", auditAccounts[i], "error", acc.Error)
+					if isBreakGlass(breakGlass, auditAccounts[i]) {
+						nonCompliant++
+					}
 					continue
 				}
-				data = append(data, []any{
-					auditAccounts[i],
-					acc.LoginProfile,
-					acc.AccessKeys,
-					acc.MfaDevices,
-					acc.SigningCertificates,
-				})
+				if acc.Partial() {
+					partial++
+					slog.Error("audit incomplete for account", "account_id", auditAccounts[i])
+				}
+				for _, warning := range acc.Warnings {
+					fmt.Fprintf(cmd.ErrOrStderr(), "Warning: account %s: %s\n", auditAccounts[i], warning)
+				}
+				row := auditRow(auditAccounts[i], acc, outputFlag)
+				if evaluateFindings {
+					findings := rootmanager.EvaluateFindings(acc, exceptions, now)
+					for _, finding := range findings {
+						if finding.Status == rootmanager.FindingOpen {
+							openFindings++
+						}
+					}
+					row = append(row, findingsCell(findings, outputFlag))
+				}
+				if breakGlassFlag != "" {
+					var result *rootmanager.BreakGlassResult
+					if isBreakGlass(breakGlass, auditAccounts[i]) {
+						checked := rootmanager.CheckBreakGlass(acc)
+						if !checked.Compliant {
+							nonCompliant++
+						}
+						result = &checked
+					}
+					row = append(row, breakGlassCell(result, outputFlag))
+				}
+				data = append(data, row)
+			}
+			for _, accountId := range slices.Sorted(maps.Keys(inactive)) {
+				notAuditable++
+				if isBreakGlass(breakGlass, accountId) {
+					nonCompliant++
+				}
+				state := fmt.Sprintf("not auditable (%s)", strings.ToLower(inactive[accountId]))
+				row := []any{accountId}
+				for range len(headers) - 1 {
+					row = append(row, state)
+				}
+				data = append(data, row)
 			}
 			output.HandleOutput(cmd.OutOrStdout(), outputFlag, headers, data)
 
-			if skipped > 0 {
-				return fmt.Errorf("audit skipped for %d account(s)", skipped)
+			var errs []error
+			if snapshotDirFlag != "" {
+				id, err := rootmanager.NewSnapshotStore(snapshotDirFlag).Save(rootmanager.Snapshot{Timestamp: time.Now(), Accounts: audit})
+				if err != nil {
+					slog.Error("failed to save audit snapshot", "error", err)
+					errs = append(errs, err)
+				} else {
+					fmt.Fprintf(cmd.ErrOrStderr(), "Saved audit snapshot %s to %s\n", id, snapshotDirFlag)
+				}
+			}
+			if skipped > 0 || partial > 0 {
+				errs = append(errs, fmt.Errorf("audit skipped for %d account(s) and incomplete for %d account(s)", skipped, partial))
+			}
+			if cancelled > 0 {
+				errs = append(errs, fmt.Errorf("audit cancelled for %d account(s)", cancelled))
+			}
+			if notAuditable > 0 {
+				fmt.Fprintf(cmd.ErrOrStderr(), "%d account(s) are not active and were not audited\n", notAuditable)
+			}
+			if nonCompliant > 0 {
+				errs = append(errs, fmt.Errorf("%d break-glass account(s) not compliant", nonCompliant))
+			}
+			if failOnFindingsFlag && openFindings > 0 {
+				errs = append(errs, fmt.Errorf("%d root credential finding(s) not covered by an active exception", openFindings))
 			}
-			return nil
+			return errors.Join(errs...)
 		},
 	}
-	cmd.PersistentFlags().StringSliceVarP(&accountsFlags, "accounts", "a", []string{}, "List of AWS account IDs to audit (comma-separated). Use \"all\" to audit all accounts.")
+	cmd.Flags().StringSliceVarP(&accountsFlags, "accounts", "a", []string{}, "List of AWS account IDs to audit (comma-separated). Use \"all\" to audit all accounts.")
+	addTargetFlags(cmd)
+	cmd.PersistentFlags().StringVar(&snapshotDirFlag, "snapshot-dir", "", "Directory where audit snapshots are stored. When set, the audit results are saved as a new snapshot.")
+	cmd.Flags().StringVar(&exceptionsFlag, "exceptions", "", "Path to a json file of accounts allowed to keep root credentials until an expiry date. Matching findings are reported as excepted.")
+	cmd.Flags().BoolVar(&failOnFindingsFlag, "fail-on-findings", false, "Exit with an error when root credentials are found that are not covered by an active exception")
+	cmd.Flags().StringVar(&breakGlassFlag, "break-glass", "", "Path to a json registry of break-glass accounts, which must have a root login profile and a hardware MFA device. Without --accounts, only these accounts are audited.")
+	cmd.AddCommand(AuditDiff())
 	return cmd
 }
+
+// isBreakGlass reports whether the account is in the break-glass registry.
+func isBreakGlass(registry []rootmanager.BreakGlassAccount, accountId string) bool {
+	return slices.ContainsFunc(registry, func(account rootmanager.BreakGlassAccount) bool {
+		return account.AccountId == accountId
+	})
+}
+
+// breakGlassCell describes the break-glass compliance of an account, nil when
+// the account is not a break-glass account. json output keeps the structured result.
+func breakGlassCell(result *rootmanager.BreakGlassResult, format string) any {
+	if format == "json" {
+		return result
+	}
+	switch {
+	case result == nil:
+		return "-"
+	case result.Compliant:
+		return "compliant"
+	default:
+		return "non-compliant: " + strings.Join(result.Issues, "; ")
+	}
+}
+
+// findingsCell describes the findings of an account. json output keeps the
+// structured findings, other formats get one line per credential type.
+func findingsCell(findings []rootmanager.Finding, format string) any {
+	if format == "json" {
+		return findings
+	}
+	formatted := make([]string, len(findings))
+	for i, finding := range findings {
+		formatted[i] = fmt.Sprintf("%s: %s", finding.CredentialType, finding.Status)
+		switch {
+		case finding.Status == rootmanager.FindingExcepted:
+			formatted[i] += fmt.Sprintf(" (owner %s, until %s)", finding.Exception.Owner, finding.Exception.Expires.Format(time.DateOnly))
+		case finding.Exception != nil:
+			formatted[i] += fmt.Sprintf(" (exception expired %s)", finding.Exception.Expires.Format(time.DateOnly))
+		}
+	}
+	return formatted
+}
+
+// loginProfileDetails is the json representation of a root login profile.
+type loginProfileDetails struct {
+	Present               bool
+	CreateDate            time.Time
+	PasswordLastUsed      time.Time
+	PasswordLastUsedError string
+}
+
+// auditRow builds the output row for an audited account, starting with label
+// (the account ID, or the snapshot ID in history). json output keeps the
+// structured credential metadata, other formats get a readable summary.
+// Checks that failed are reported with their error instead of a result.
+func auditRow(label string, acc rootmanager.RootCredentials, format string) []any {
+	var row []any
+	if format == "json" {
+		row = []any{
+			label,
+			loginProfileDetails{
+				Present:               acc.LoginProfile,
+				CreateDate:            acc.LoginProfileCreateDate,
+				PasswordLastUsed:      acc.PasswordLastUsed,
+				PasswordLastUsedError: acc.PasswordLastUsedError,
+			},
+			acc.AccessKeys,
+			acc.MfaDevices,
+			acc.SigningCertificates,
+		}
+	} else {
+		row = []any{
+			label,
+			formatLoginProfile(acc),
+			formatAccessKeys(acc.AccessKeys),
+			formatMFADevices(acc.MfaDevices),
+			formatSigningCertificates(acc.SigningCertificates),
+		}
+	}
+
+	checks := []string{rootmanager.CheckLogin, rootmanager.CheckKeys, rootmanager.CheckMFA, rootmanager.CheckCertificates}
+	for i, check := range checks {
+		if checkErr := acc.CheckError(check); checkErr != "" {
+			row[i+1] = "check failed: " + checkErr
+		}
+	}
+	return row
+}
+
+// formatLoginProfile describes the login profile with its creation date and the
+// last time the root password was used. Returns nil when there is no login profile.
+func formatLoginProfile(acc rootmanager.RootCredentials) []string {
+	if !acc.LoginProfile {
+		return nil
+	}
+	lastUsed := "password never used"
+	switch {
+	case acc.PasswordLastUsedError != "":
+		lastUsed = "password last used unknown"
+	case !acc.PasswordLastUsed.IsZero():
+		lastUsed = "password last used " + acc.PasswordLastUsed.Format(time.DateOnly)
+	}
+	return []string{fmt.Sprintf("created %s, %s", acc.LoginProfileCreateDate.Format(time.DateOnly), lastUsed)}
+}
+
+// formatAccessKeys describes each access key with its status, age and last usage.
+func formatAccessKeys(keys []rootmanager.AccessKey) []string {
+	formatted := make([]string, len(keys))
+	for i, key := range keys {
+		lastUsed := "never used"
+		switch {
+		case key.LastUsedError != "":
+			lastUsed = "last used unknown"
+		case !key.LastUsedDate.IsZero():
+			lastUsed = fmt.Sprintf("last used %s in %s/%s", key.LastUsedDate.Format(time.DateOnly), key.LastUsedService, key.LastUsedRegion)
+		}
+		age := int(time.Since(key.CreateDate).Hours() / 24)
+		formatted[i] = fmt.Sprintf("%s (%s, %dd old, %s)", key.AccessKeyId, key.Status, age, lastUsed)
+	}
+	return formatted
+}
+
+// formatMFADevices describes each MFA device with its type and enable date.
+func formatMFADevices(devices []rootmanager.MFADevice) []string {
+	formatted := make([]string, len(devices))
+	for i, device := range devices {
+		formatted[i] = fmt.Sprintf("%s (%s, enabled %s)", device.SerialNumber, device.Type, device.EnableDate.Format(time.DateOnly))
+	}
+	return formatted
+}
+
+// formatSigningCertificates describes each signing certificate with its status and upload date.
+func formatSigningCertificates(certificates []rootmanager.SigningCertificate) []string {
+	formatted := make([]string, len(certificates))
+	for i, certificate := range certificates {
+		formatted[i] = fmt.Sprintf("%s (%s, uploaded %s)", certificate.CertificateId, certificate.Status, certificate.UploadDate.Format(time.DateOnly))
+	}
+	return formatted
+}
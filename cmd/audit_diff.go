@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/unicrons/aws-root-manager/internal/cli/output"
+	"github.com/unicrons/aws-root-manager/rootmanager"
+
+	"github.com/spf13/cobra"
+)
+
+func AuditDiff() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "diff [FROM_SNAPSHOT TO_SNAPSHOT]",
+		Short: "Compare root credentials between audit snapshots",
+		Long: `Report the root credentials that appeared, disappeared or changed between two audit snapshots.
+Without arguments, the two most recent snapshots are compared.`,
+		Args:         cobra.MatchAll(cobra.MaximumNArgs(2), validateDiffArgs),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			slog.Debug("audit diff called")
+
+			if snapshotDirFlag == "" {
+				return errors.New("--snapshot-dir is required")
+			}
+			store := rootmanager.NewSnapshotStore(snapshotDirFlag)
+
+			fromId, toId, err := diffSnapshotIds(store, args)
+			if err != nil {
+				return err
+			}
+			from, err := store.Load(fromId)
+			if err != nil {
+				return err
+			}
+			to, err := store.Load(toId)
+			if err != nil {
+				return err
+			}
+
+			headers := []string{"Account", "CredentialType", "Credential", "Change", "Details"}
+			var data [][]any
+			for _, change := range rootmanager.DiffSnapshots(from, to) {
+				data = append(data, []any{change.AccountId, change.CredentialType, change.CredentialId, change.Change, change.Details})
+			}
+			fmt.Fprintf(cmd.ErrOrStderr(), "Comparing snapshot %s with %s\n", fromId, toId)
+			output.HandleOutput(cmd.OutOrStdout(), outputFlag, headers, data)
+			return nil
+		},
+	}
+	return cmd
+}
+
+// validateDiffArgs requires either no snapshot or both snapshots to compare.
+func validateDiffArgs(_ *cobra.Command, args []string) error {
+	if len(args) == 1 {
+		return errors.New("both FROM_SNAPSHOT and TO_SNAPSHOT are required when comparing specific snapshots")
+	}
+	return nil
+}
+
+// diffSnapshotIds returns the snapshots to compare: the ones given as
+// arguments, or the two most recent snapshots in the store.
+func diffSnapshotIds(store *rootmanager.SnapshotStore, args []string) (string, string, error) {
+	if len(args) == 2 {
+		return args[0], args[1], nil
+	}
+	ids, err := store.List()
+	if err != nil {
+		return "", "", err
+	}
+	if len(ids) < 2 {
+		return "", "", fmt.Errorf("at least two snapshots are required in %s, found %d", snapshotDirFlag, len(ids))
+	}
+	return ids[len(ids)-2], ids[len(ids)-1], nil
+}
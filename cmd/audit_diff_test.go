@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/unicrons/aws-root-manager/rootmanager"
+)
+
+// saveSnapshots stores one snapshot per accounts slice, an hour apart, and returns their IDs.
+func saveSnapshots(t *testing.T, dir string, snapshots ...[]rootmanager.RootCredentials) []string {
+	t.Helper()
+	store := rootmanager.NewSnapshotStore(dir)
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	var ids []string
+	for i, accounts := range snapshots {
+		id, err := store.Save(rootmanager.Snapshot{Timestamp: start.Add(time.Duration(i) * time.Hour), Accounts: accounts})
+		require.NoError(t, err)
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+func TestAuditDiffCommand_LatestSnapshots(t *testing.T) {
+	setOutputFlag(t, "csv")
+	dir := t.TempDir()
+	saveSnapshots(t, dir,
+		[]rootmanager.RootCredentials{{AccountId: "123456789012", AccessKeys: []rootmanager.AccessKey{{AccessKeyId: "AKIAOLD"}}}},
+		[]rootmanager.RootCredentials{{AccountId: "123456789012"}},
+		[]rootmanager.RootCredentials{{AccountId: "123456789012", LoginProfile: true}},
+	)
+
+	var buf bytes.Buffer
+	cmd := Audit(newMockFactory(&mockRootManager{}))
+	cmd.SetOut(&buf)
+	cmd.SetErr(&bytes.Buffer{})
+	cmd.SetArgs([]string{"diff", "--snapshot-dir", dir})
+
+	require.NoError(t, cmd.Execute())
+	assert.Contains(t, buf.String(), "123456789012,login,,appeared,")
+	assert.NotContains(t, buf.String(), "AKIAOLD")
+}
+
+func TestAuditDiffCommand_GivenSnapshots(t *testing.T) {
+	setOutputFlag(t, "csv")
+	dir := t.TempDir()
+	ids := saveSnapshots(t, dir,
+		[]rootmanager.RootCredentials{{AccountId: "123456789012", AccessKeys: []rootmanager.AccessKey{{AccessKeyId: "AKIAOLD"}}}},
+		[]rootmanager.RootCredentials{{AccountId: "123456789012"}},
+		[]rootmanager.RootCredentials{{AccountId: "123456789012", LoginProfile: true}},
+	)
+
+	var buf bytes.Buffer
+	cmd := Audit(newMockFactory(&mockRootManager{}))
+	cmd.SetOut(&buf)
+	cmd.SetErr(&bytes.Buffer{})
+	cmd.SetArgs([]string{"diff", ids[0], ids[1], "--snapshot-dir", dir})
+
+	require.NoError(t, cmd.Execute())
+	assert.Contains(t, buf.String(), "123456789012,keys,AKIAOLD,disappeared,")
+}
+
+func TestAuditDiffCommand_NotEnoughSnapshots(t *testing.T) {
+	dir := t.TempDir()
+	saveSnapshots(t, dir, []rootmanager.RootCredentials{{AccountId: "123456789012"}})
+
+	cmd := Audit(newMockFactory(&mockRootManager{}))
+	cmd.SilenceErrors = true
+	cmd.SetArgs([]string{"diff", "--snapshot-dir", dir})
+
+	err := cmd.Execute()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "at least two snapshots are required")
+}
+
+func TestAuditDiffCommand_SingleSnapshotArgument(t *testing.T) {
+	cmd := Audit(newMockFactory(&mockRootManager{}))
+	cmd.SilenceErrors = true
+	cmd.SilenceUsage = true
+	cmd.SetArgs([]string{"diff", "20250101T000000Z", "--snapshot-dir", t.TempDir()})
+
+	require.Error(t, cmd.Execute())
+}
+
+func TestAuditDiffCommand_InvalidSnapshotId(t *testing.T) {
+	dir := t.TempDir()
+	ids := saveSnapshots(t, dir, []rootmanager.RootCredentials{{AccountId: "123456789012"}})
+
+	cmd := Audit(newMockFactory(&mockRootManager{}))
+	cmd.SilenceErrors = true
+	cmd.SilenceUsage = true
+	cmd.SetArgs([]string{"diff", "../../x", ids[0], "--snapshot-dir", dir})
+
+	err := cmd.Execute()
+	assert.ErrorIs(t, err, rootmanager.ErrInvalidSnapshotId)
+}
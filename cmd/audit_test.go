@@ -3,7 +3,10 @@ package cmd
 import (
 	"bytes"
 	"errors"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -13,7 +16,7 @@ import (
 func TestAuditCommand_Success(t *testing.T) {
 	mock := &mockRootManager{
 		auditResult: []rootmanager.RootCredentials{
-			{AccountId: "123456789012", LoginProfile: false, AccessKeys: []string{}, MfaDevices: []string{}, SigningCertificates: []string{}},
+			{AccountId: "123456789012", LoginProfile: false, AccessKeys: []rootmanager.AccessKey{}, MfaDevices: []rootmanager.MFADevice{}, SigningCertificates: []rootmanager.SigningCertificate{}},
 		},
 	}
 
@@ -64,3 +67,404 @@ func TestAuditCommand_SkippedAccounts(t *testing.T) {
 
 	require.Error(t, cmd.Execute())
 }
+
+func TestAuditCommand_PartialAccount(t *testing.T) {
+	mock := &mockRootManager{
+		auditResult: []rootmanager.RootCredentials{
+			{
+				AccountId:    "123456789012",
+				LoginProfile: true,
+				Checks: []rootmanager.CheckResult{
+					{Check: rootmanager.CheckLogin, Success: true},
+					{Check: rootmanager.CheckKeys, Success: true},
+					{Check: rootmanager.CheckMFA, Success: true},
+					{Check: rootmanager.CheckCertificates, Success: false, Error: "throttled"},
+				},
+			},
+		},
+	}
+	setOutputFlag(t, "csv")
+
+	var buf bytes.Buffer
+	cmd := Audit(newMockFactory(mock))
+	cmd.SilenceErrors = true
+	cmd.SetOut(&buf)
+	cmd.SetArgs([]string{"--accounts", "123456789012"})
+
+	require.Error(t, cmd.Execute())
+	assert.Contains(t, buf.String(), "123456789012")
+	assert.Contains(t, buf.String(), "check failed: throttled")
+}
+
+func TestAuditCommand_AccessKeyDetails(t *testing.T) {
+	mock := &mockRootManager{
+		auditResult: []rootmanager.RootCredentials{
+			{AccountId: "123456789012", AccessKeys: []rootmanager.AccessKey{{
+				AccessKeyId:     "AKIA123",
+				Status:          "Active",
+				CreateDate:      time.Now().AddDate(0, 0, -10),
+				LastUsedDate:    time.Date(2024, 5, 6, 0, 0, 0, 0, time.UTC),
+				LastUsedService: "s3",
+				LastUsedRegion:  "us-east-1",
+			}}},
+		},
+	}
+
+	tests := []struct {
+		format string
+		want   []string
+	}{
+		{"table", []string{"AKIA123 (Active, 10d old"}},
+		{"csv", []string{"AKIA123 (Active, 10d old, last used 2024-05-06 in s3/us-east-1)"}},
+		{"json", []string{`"AccessKeyId": "AKIA123"`, `"Status": "Active"`, `"LastUsedService": "s3"`}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			setOutputFlag(t, tt.format)
+
+			var buf bytes.Buffer
+			cmd := Audit(newMockFactory(mock))
+			cmd.SetOut(&buf)
+			cmd.SetArgs([]string{"--accounts", "123456789012"})
+
+			require.NoError(t, cmd.Execute())
+			for _, want := range tt.want {
+				assert.Contains(t, buf.String(), want)
+			}
+		})
+	}
+}
+
+func TestAuditCommand_MFADeviceDetails(t *testing.T) {
+	mock := &mockRootManager{
+		auditResult: []rootmanager.RootCredentials{
+			{AccountId: "123456789012", MfaDevices: []rootmanager.MFADevice{{
+				SerialNumber: "GAHT12345678",
+				Type:         rootmanager.MFADeviceTypeHardware,
+				EnableDate:   time.Date(2023, 2, 3, 0, 0, 0, 0, time.UTC),
+			}}},
+		},
+	}
+
+	tests := []struct {
+		format string
+		want   []string
+	}{
+		{"csv", []string{"GAHT12345678 (hardware, enabled 2023-02-03)"}},
+		{"json", []string{`"SerialNumber": "GAHT12345678"`, `"Type": "hardware"`}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			setOutputFlag(t, tt.format)
+
+			var buf bytes.Buffer
+			cmd := Audit(newMockFactory(mock))
+			cmd.SetOut(&buf)
+			cmd.SetArgs([]string{"--accounts", "123456789012"})
+
+			require.NoError(t, cmd.Execute())
+			for _, want := range tt.want {
+				assert.Contains(t, buf.String(), want)
+			}
+		})
+	}
+}
+
+func TestAuditCommand_LoginProfileDetails(t *testing.T) {
+	mock := &mockRootManager{
+		auditResult: []rootmanager.RootCredentials{
+			{
+				AccountId:              "123456789012",
+				LoginProfile:           true,
+				LoginProfileCreateDate: time.Date(2019, 7, 8, 0, 0, 0, 0, time.UTC),
+				PasswordLastUsed:       time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC),
+			},
+		},
+	}
+
+	tests := []struct {
+		format string
+		want   []string
+	}{
+		{"csv", []string{"created 2019-07-08, password last used 2024-01-02"}},
+		{"json", []string{`"Present": true`, `"CreateDate": "2019-07-08T00:00:00Z"`, `"PasswordLastUsed": "2024-01-02T00:00:00Z"`}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			setOutputFlag(t, tt.format)
+
+			var buf bytes.Buffer
+			cmd := Audit(newMockFactory(mock))
+			cmd.SetOut(&buf)
+			cmd.SetArgs([]string{"--accounts", "123456789012"})
+
+			require.NoError(t, cmd.Execute())
+			for _, want := range tt.want {
+				assert.Contains(t, buf.String(), want)
+			}
+		})
+	}
+}
+
+func TestAuditCommand_SigningCertificateDetails(t *testing.T) {
+	mock := &mockRootManager{
+		auditResult: []rootmanager.RootCredentials{
+			{AccountId: "123456789012", SigningCertificates: []rootmanager.SigningCertificate{{
+				CertificateId: "CERT123",
+				Status:        "Active",
+				UploadDate:    time.Date(2018, 3, 4, 0, 0, 0, 0, time.UTC),
+			}}},
+		},
+	}
+
+	tests := []struct {
+		format string
+		want   []string
+	}{
+		{"csv", []string{"CERT123 (Active, uploaded 2018-03-04)"}},
+		{"json", []string{`"CertificateId": "CERT123"`, `"Status": "Active"`, `"UploadDate": "2018-03-04T00:00:00Z"`}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			setOutputFlag(t, tt.format)
+
+			var buf bytes.Buffer
+			cmd := Audit(newMockFactory(mock))
+			cmd.SetOut(&buf)
+			cmd.SetArgs([]string{"--accounts", "123456789012"})
+
+			require.NoError(t, cmd.Execute())
+			for _, want := range tt.want {
+				assert.Contains(t, buf.String(), want)
+			}
+		})
+	}
+}
+
+func TestAuditCommand_CancelledAccounts(t *testing.T) {
+	setOutputFlag(t, "csv")
+	mock := &mockRootManager{
+		auditResult: []rootmanager.RootCredentials{
+			{AccountId: "111111111111", Checks: []rootmanager.CheckResult{{Check: rootmanager.CheckLogin, Success: true}}},
+			{AccountId: "222222222222", Cancelled: true, Error: "not processed: interrupted by interrupt"},
+		},
+	}
+
+	var buf bytes.Buffer
+	cmd := Audit(newMockFactory(mock))
+	cmd.SilenceErrors = true
+	cmd.SetOut(&buf)
+	cmd.SetArgs([]string{"--accounts", "111111111111,222222222222"})
+
+	err := cmd.Execute()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "audit cancelled for 1 account(s)")
+	assert.Contains(t, buf.String(), "111111111111")
+	assert.Contains(t, buf.String(), "222222222222,cancelled,cancelled,cancelled,cancelled")
+}
+
+func TestAuditCommand_SavesSnapshot(t *testing.T) {
+	dir := t.TempDir()
+	mock := &mockRootManager{
+		auditResult: []rootmanager.RootCredentials{{AccountId: "123456789012", LoginProfile: true}},
+	}
+
+	var stderr bytes.Buffer
+	cmd := Audit(newMockFactory(mock))
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetErr(&stderr)
+	cmd.SetArgs([]string{"--accounts", "123456789012", "--snapshot-dir", dir})
+
+	require.NoError(t, cmd.Execute())
+
+	store := rootmanager.NewSnapshotStore(dir)
+	ids, err := store.List()
+	require.NoError(t, err)
+	require.Len(t, ids, 1)
+	assert.Contains(t, stderr.String(), "Saved audit snapshot "+ids[0])
+
+	snapshot, err := store.Load(ids[0])
+	require.NoError(t, err)
+	assert.Equal(t, mock.auditResult, snapshot.Accounts)
+}
+
+func TestAuditCommand_Exceptions(t *testing.T) {
+	setOutputFlag(t, "csv")
+	exceptionsPath := filepath.Join(t.TempDir(), "exceptions.json")
+	require.NoError(t, os.WriteFile(exceptionsPath, []byte(`{"exceptions": [
+		{"account_id": "123456789012", "credential_type": "login", "justification": "break-glass", "owner": "security", "expires": "2999-12-31"},
+		{"account_id": "123456789012", "credential_type": "mfa", "justification": "break-glass", "owner": "security", "expires": "2020-01-01"}
+	]}`), 0o600))
+	mock := &mockRootManager{
+		auditResult: []rootmanager.RootCredentials{{
+			AccountId:    "123456789012",
+			LoginProfile: true,
+			MfaDevices:   []rootmanager.MFADevice{{SerialNumber: "GAHT1", Type: rootmanager.MFADeviceTypeHardware}},
+		}},
+	}
+
+	var buf bytes.Buffer
+	cmd := Audit(newMockFactory(mock))
+	cmd.SilenceErrors = true
+	cmd.SetOut(&buf)
+	cmd.SetArgs([]string{"--accounts", "123456789012", "--exceptions", exceptionsPath, "--fail-on-findings"})
+
+	err := cmd.Execute()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "1 root credential finding(s) not covered by an active exception")
+	assert.Contains(t, buf.String(), "Findings")
+	assert.Contains(t, buf.String(), "login: excepted (owner security, until 2999-12-31)")
+	assert.Contains(t, buf.String(), "mfa: open (exception expired 2020-01-01)")
+}
+
+func TestAuditCommand_ExceptionsCoverAllFindings(t *testing.T) {
+	exceptionsPath := filepath.Join(t.TempDir(), "exceptions.json")
+	require.NoError(t, os.WriteFile(exceptionsPath, []byte(`{"exceptions": [
+		{"account_id": "123456789012", "credential_type": "all", "justification": "break-glass", "owner": "security", "expires": "2999-12-31"}
+	]}`), 0o600))
+	mock := &mockRootManager{
+		auditResult: []rootmanager.RootCredentials{{AccountId: "123456789012", LoginProfile: true}},
+	}
+
+	cmd := Audit(newMockFactory(mock))
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetArgs([]string{"--accounts", "123456789012", "--exceptions", exceptionsPath, "--fail-on-findings"})
+
+	require.NoError(t, cmd.Execute())
+}
+
+func TestAuditCommand_InvalidExceptionsFile(t *testing.T) {
+	exceptionsPath := filepath.Join(t.TempDir(), "exceptions.json")
+	require.NoError(t, os.WriteFile(exceptionsPath, []byte(`{"exceptions": [{"account_id": "123"}]}`), 0o600))
+
+	cmd := Audit(newMockFactory(&mockRootManager{}))
+	cmd.SilenceErrors = true
+	cmd.SetArgs([]string{"--accounts", "123456789012", "--exceptions", exceptionsPath})
+
+	err := cmd.Execute()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid account_id")
+}
+
+func TestAuditCommand_BreakGlass(t *testing.T) {
+	setOutputFlag(t, "csv")
+	registryPath := filepath.Join(t.TempDir(), "break-glass.json")
+	require.NoError(t, os.WriteFile(registryPath, []byte(`{"accounts": [{"account_id": "111111111111"}, {"account_id": "222222222222"}]}`), 0o600))
+	mock := &mockRootManager{
+		auditResult: []rootmanager.RootCredentials{
+			{AccountId: "111111111111", LoginProfile: true, MfaDevices: []rootmanager.MFADevice{{SerialNumber: "GAHT1", Type: rootmanager.MFADeviceTypeHardware}}},
+			{AccountId: "222222222222", LoginProfile: true, MfaDevices: []rootmanager.MFADevice{{SerialNumber: "arn:aws:iam::222222222222:mfa/root", Type: rootmanager.MFADeviceTypeVirtual}}},
+			{AccountId: "333333333333"},
+		},
+	}
+
+	var buf bytes.Buffer
+	cmd := Audit(newMockFactory(mock))
+	cmd.SilenceErrors = true
+	cmd.SetOut(&buf)
+	cmd.SetArgs([]string{"--accounts", "111111111111,222222222222,333333333333", "--break-glass", registryPath})
+
+	err := cmd.Execute()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "1 break-glass account(s) not compliant")
+	out := buf.String()
+	assert.Contains(t, out, "Break-glass")
+	assert.Regexp(t, `111111111111,.*,compliant\n`, out)
+	assert.Regexp(t, `222222222222,.*,non-compliant: no hardware MFA device \(found virtual\)\n`, out)
+	assert.Regexp(t, `333333333333,.*,-\n`, out)
+}
+
+func TestAuditCommand_BreakGlassDefaultsToRegistryAccounts(t *testing.T) {
+	registryPath := filepath.Join(t.TempDir(), "break-glass.json")
+	require.NoError(t, os.WriteFile(registryPath, []byte(`{"accounts": [{"account_id": "111111111111"}]}`), 0o600))
+	mock := &mockRootManager{
+		auditResult: []rootmanager.RootCredentials{
+			{AccountId: "111111111111", LoginProfile: true, MfaDevices: []rootmanager.MFADevice{{SerialNumber: "GAHT1", Type: rootmanager.MFADeviceTypeHardware}}},
+		},
+	}
+
+	cmd := Audit(newMockFactory(mock))
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetArgs([]string{"--break-glass", registryPath})
+
+	require.NoError(t, cmd.Execute())
+}
+
+func TestAuditCommand_Warnings(t *testing.T) {
+	mock := &mockRootManager{
+		auditResult: []rootmanager.RootCredentials{
+			{
+				AccountId:             "123456789012",
+				LoginProfile:          true,
+				PasswordLastUsedError: "access denied",
+				AccessKeys:            []rootmanager.AccessKey{{AccessKeyId: "AKIA1", Status: "Active", LastUsedError: "throttled"}},
+				Warnings:              []string{"password last used unknown: access denied", "throttled"},
+			},
+		},
+	}
+	setOutputFlag(t, "csv")
+
+	var out, errOut bytes.Buffer
+	cmd := Audit(newMockFactory(mock))
+	cmd.SetOut(&out)
+	cmd.SetErr(&errOut)
+	cmd.SetArgs([]string{"--accounts", "123456789012"})
+
+	require.NoError(t, cmd.Execute())
+	assert.Contains(t, errOut.String(), "Warning: account 123456789012: password last used unknown: access denied")
+	// a last usage that could not be retrieved is not reported as never used
+	assert.Contains(t, out.String(), "password last used unknown")
+	assert.Contains(t, out.String(), "last used unknown)")
+	assert.NotContains(t, out.String(), "never used")
+}
+
+func TestAuditCommand_LastUsedUnknownJSON(t *testing.T) {
+	mock := &mockRootManager{
+		auditResult: []rootmanager.RootCredentials{
+			{AccountId: "123456789012", LoginProfile: true, PasswordLastUsedError: "access denied"},
+		},
+	}
+	setOutputFlag(t, "json")
+
+	var out bytes.Buffer
+	cmd := Audit(newMockFactory(mock))
+	cmd.SetOut(&out)
+	cmd.SetErr(&bytes.Buffer{})
+	cmd.SetArgs([]string{"--accounts", "123456789012"})
+
+	require.NoError(t, cmd.Execute())
+	assert.Contains(t, out.String(), `"PasswordLastUsedError": "access denied"`)
+}
+
+func TestAuditCommand_NotAuditableAccounts(t *testing.T) {
+	setOutputFlag(t, "csv")
+	setOrganizationsClient(t, newStatusesMock())
+	mock := &mockRootManager{
+		auditResult: []rootmanager.RootCredentials{
+			{
+				AccountId: "123456789012",
+				Checks: []rootmanager.CheckResult{
+					{Check: rootmanager.CheckLogin, Success: true},
+					{Check: rootmanager.CheckKeys, Success: true},
+					{Check: rootmanager.CheckMFA, Success: true},
+					{Check: rootmanager.CheckCertificates, Success: true},
+				},
+			},
+		},
+	}
+
+	var out, errOut bytes.Buffer
+	cmd := Audit(newMockFactory(mock))
+	cmd.SetOut(&out)
+	cmd.SetErr(&errOut)
+	cmd.SetArgs([]string{"--accounts", "all", "--include-status", "suspended,pending_closure"})
+
+	require.NoError(t, cmd.Execute())
+	assert.Equal(t, []string{"123456789012"}, mock.auditedAccounts)
+	assert.Equal(t, "Account,LoginProfile,AccessKeys,MFA Devices,Signing Certificates\n"+
+		"123456789012,[],[],[],[]\n"+
+		"234567890123,not auditable (suspended),not auditable (suspended),not auditable (suspended),not auditable (suspended)\n"+
+		"345678901234,not auditable (pending_closure),not auditable (pending_closure),not auditable (pending_closure),not auditable (pending_closure)\n",
+		out.String())
+	assert.Contains(t, errOut.String(), "2 account(s) are not active and were not audited")
+}
@@ -19,7 +19,9 @@ func Check(newRM func(context.Context) (rootmanager.RootManager, error)) *cobra.
 		RunE: func(cmd *cobra.Command, args []string) error {
 			slog.Debug("check called")
 
-			ctx := context.Background()
+			ctx, cancel := commandContext()
+			defer cancel()
+
 			rm, err := newRM(ctx)
 			if err != nil {
 				slog.Error("failed to initialize root manager", "error", err)
@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// commandContext returns the context commands run with. It is cancelled on the
+// first interrupt or termination signal, and after --timeout when set, so
+// multi-account operations stop starting new accounts and report the partial
+// results. A second signal terminates the process immediately.
+func commandContext() (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancelCause(context.Background())
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		select {
+		case sig := <-signals:
+			// restore the default behaviour so a second signal exits
+			signal.Stop(signals)
+			fmt.Fprintln(os.Stderr, "Interrupted, waiting for in-flight accounts to finish. Interrupt again to exit immediately.")
+			cancel(fmt.Errorf("interrupted by %s", sig))
+		case <-ctx.Done():
+		}
+	}()
+
+	stop := func() {
+		signal.Stop(signals)
+		cancel(nil)
+	}
+	if timeoutFlag <= 0 {
+		return ctx, stop
+	}
+
+	timeoutCtx, cancelTimeout := context.WithTimeoutCause(ctx, timeoutFlag, fmt.Errorf("timeout of %s exceeded", timeoutFlag))
+	return timeoutCtx, func() {
+		cancelTimeout()
+		stop()
+	}
+}
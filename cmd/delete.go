@@ -2,12 +2,14 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
+	"slices"
 	"strings"
+	"time"
 
-	"github.com/unicrons/aws-root-manager/internal/aws"
 	"github.com/unicrons/aws-root-manager/internal/cli/output"
 	"github.com/unicrons/aws-root-manager/internal/cli/ui"
 	"github.com/unicrons/aws-root-manager/rootmanager"
@@ -26,9 +28,11 @@ func Delete(newRM func(context.Context) (rootmanager.RootManager, error)) *cobra
 	cmd.AddCommand(deleteSubcommand(newRM, "keys", "Delete root user Access Keys", "Delete existing root user Access Keys for specific AWS Organization member accounts."))
 	cmd.AddCommand(deleteSubcommand(newRM, "mfa", "Deactivate root user MFA Devices", "Deactivate existing root user MFA Devices for specific AWS Organization member accounts."))
 	cmd.AddCommand(deleteSubcommand(newRM, "certificates", "Delete root user Signin Certificates", "Delete existing root user Signing Certificates for specific AWS Organization member accounts."))
+	cmd.AddCommand(DeleteApply(newRM))
 	cmd.AddCommand(DeleteS3BucketPolicy(newRM))
 	cmd.AddCommand(DeleteSQSQueuePolicy(newRM))
 	cmd.PersistentFlags().BoolVar(&skipFlag, "yes", false, "Skip the confirmation prompt")
+	cmd.PersistentFlags().BoolVar(&dryRunFlag, "dry-run", false, "Show what would be deleted without making any changes")
 	return cmd
 }
 
@@ -44,34 +48,77 @@ func deleteSubcommand(newRM func(context.Context) (rootmanager.RootManager, erro
 		Long:         long,
 		SilenceUsage: true,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runDelete(newRM, cmd.OutOrStdout(), accounts, credentialType)
+			return runDelete(newRM, cmd.OutOrStdout(), cmd.ErrOrStderr(), accounts, credentialType)
 		},
 	}
 	cmd.Flags().StringSliceVarP(&accounts, "accounts", "a", []string{}, "List of AWS account IDs (comma-separated). Use \"all\" to select all accounts.")
+	addTargetFlags(cmd)
+	cmd.Flags().StringVar(&planOutFlag, "plan-out", "", "Audit the accounts and save the credentials that would be deleted to this plan file instead of deleting them. Apply it with \"delete apply\".")
+	switch credentialType {
+	case rootmanager.CheckKeys:
+		cmd.Flags().StringSliceVar(&itemIdsFlag, "key-id", []string{}, "Only delete the root access keys with these IDs (comma-separated)")
+	case rootmanager.CheckMFA:
+		cmd.Flags().StringSliceVar(&itemIdsFlag, "mfa-serial", []string{}, "Only deactivate the root MFA devices with these serial numbers (comma-separated)")
+	case rootmanager.CheckCertificates:
+		cmd.Flags().StringSliceVar(&itemIdsFlag, "certificate-id", []string{}, "Only delete the root signing certificates with these IDs (comma-separated)")
+	}
+	if credentialType == rootmanager.CredentialTypeAll {
+		cmd.Flags().StringSliceVar(&typesFlag, "types", []string{}, "Only delete these credential types (comma-separated: login, keys, mfa, certificates)")
+	}
+	if credentialType != rootmanager.CheckLogin {
+		cmd.Flags().BoolVar(&pickItemsFlag, "pick", false, "Audit the accounts and select the root credentials to delete interactively")
+	}
 	return cmd
 }
 
-func runDelete(newRM func(context.Context) (rootmanager.RootManager, error), w io.Writer, accountsFlags []string, credentialType string) error {
-	ctx := context.Background()
+func runDelete(newRM func(context.Context) (rootmanager.RootManager, error), w, errW io.Writer, accountsFlags []string, credentialType string) error {
+	if len(typesFlag) > 0 {
+		types, err := parseCredentialTypes(typesFlag)
+		if err != nil {
+			return err
+		}
+		credentialType = types
+	}
+	itemSelection := len(itemIdsFlag) > 0 || pickItemsFlag
+	if itemSelection && planOutFlag != "" {
+		return errors.New("--plan-out cannot be combined with credential IDs or --pick")
+	}
+
+	ctx, cancel := commandContext()
+	defer cancel()
+
 	rm, err := newRM(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to initialize root manager: %w", err)
 	}
 
-	awscfg, err := aws.LoadAWSConfig(ctx)
+	selection, err := accountSelection(accountsFlags)
+	if err != nil {
+		return err
+	}
+
+	org, orgAccounts, err := organizationAccounts(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to load aws config: %w", err)
+		return err
 	}
-	auditAccounts, err := ui.SelectTargetAccounts(ctx, aws.NewOrganizationsClient(awscfg), accountsFlags)
+	auditAccounts, err := ui.SelectTargetAccounts(ctx, org, orgAccounts, selection)
 	if err != nil {
 		return fmt.Errorf("failed to get accounts to audit: %w", err)
 	}
+	auditAccounts = activeAccounts(orgAccounts, auditAccounts, errW)
 	if len(auditAccounts) == 0 {
 		slog.Info("no accounts selected")
 		return nil
 	}
 	slog.Debug("selected accounts", "accounts", strings.Join(auditAccounts, ", "))
 
+	if itemSelection {
+		return runDeleteItems(ctx, rm, w, errW, auditAccounts, credentialType)
+	}
+	if dryRunFlag || planOutFlag != "" {
+		return runDeletePlan(ctx, rm, w, errW, auditAccounts, credentialType, planOutFlag)
+	}
+
 	if !skipFlag {
 		confirmed, err := ui.Confirm(fmt.Sprintf("Delete %s root credentials for %d account(s)?", credentialType, len(auditAccounts)))
 		if err != nil {
@@ -83,39 +130,198 @@ func runDelete(newRM func(context.Context) (rootmanager.RootManager, error), w i
 		}
 	}
 
-	audit, err := rm.AuditAccounts(ctx, auditAccounts)
+	progressCtx, stopProgress := withProgress(ctx)
+	audit, err := rm.AuditAccounts(progressCtx, auditAccounts)
 	if err != nil {
+		stopProgress()
 		return err
 	}
 
-	results, err := rm.DeleteCredentials(ctx, audit, credentialType)
+	results, err := rm.DeleteCredentials(progressCtx, audit, credentialType)
+	stopProgress()
 	if err != nil {
 		return err
 	}
+	results = verifyDeletion(ctx, rm, results)
+
+	data, counts := deletionRows(results)
+	output.HandleOutput(w, outputFlag, deletionHeaders, data)
+	return errors.Join(counts.errs()...)
+}
+
+// parseCredentialTypes converts the credential types of the --types flag into a
+// credential type accepted by DeleteCredentials, such as "login,keys,certificate".
+// The types are deleted in their usual order, whatever the order they are given in.
+func parseCredentialTypes(types []string) (string, error) {
+	var parsed []string
+	for _, t := range types {
+		t = strings.ToLower(strings.TrimSpace(t))
+		if t == "certificates" {
+			t = rootmanager.CheckCertificates
+		}
+		if !slices.Contains(rootmanager.CredentialTypes, t) {
+			return "", fmt.Errorf("invalid credential type %q, expected login, keys, mfa or certificates", t)
+		}
+		if !slices.Contains(parsed, t) {
+			parsed = append(parsed, t)
+		}
+	}
+	if len(parsed) == len(rootmanager.CredentialTypes) {
+		return rootmanager.CredentialTypeAll, nil
+	}
+	slices.SortFunc(parsed, func(a, b string) int {
+		return slices.Index(rootmanager.CredentialTypes, a) - slices.Index(rootmanager.CredentialTypes, b)
+	})
+	return strings.Join(parsed, ","), nil
+}
+
+// deletionHeaders are the output headers of deletion results.
+var deletionHeaders = []string{"Account", "CredentialType", "Credential", "Status", "Verified", "Error"}
+
+// deletionCounts counts the accounts and credentials of deletion results that
+// did not end as expected.
+type deletionCounts struct {
+	failed     int // Accounts whose deletion failed
+	cancelled  int // Accounts not processed because the deletion was cancelled
+	unverified int // Deleted credentials still present after the deletion
+	unknown    int // Deleted credentials that could not be verified
+}
+
+// errs returns an error for every non-zero count.
+func (c deletionCounts) errs() []error {
+	var errs []error
+	if c.failed > 0 {
+		errs = append(errs, fmt.Errorf("deletion failed for %d account(s)", c.failed))
+	}
+	if c.cancelled > 0 {
+		errs = append(errs, fmt.Errorf("deletion cancelled for %d account(s)", c.cancelled))
+	}
+	if c.unverified > 0 {
+		errs = append(errs, fmt.Errorf("verification failed: %d root credential(s) still present after deletion", c.unverified))
+	}
+	if c.unknown > 0 {
+		errs = append(errs, fmt.Errorf("verification incomplete: %d deleted root credential(s) could not be audited again", c.unknown))
+	}
+	return errs
+}
 
-	headers := []string{"Account", "CredentialType", "Status", "Error"}
-	var data [][]any
-	var failureCount int
+// deletionRows builds the output rows of deletion results, one per credential
+// the deletion attempted, and counts the results that need attention.
+func deletionRows(results []rootmanager.DeletionResult) (data [][]any, counts deletionCounts) {
 	for _, result := range results {
-		status := "deleted"
+		status := "nothing to delete"
 		errorMsg := ""
-		if !result.Success {
+		switch {
+		case result.Cancelled:
+			status = "cancelled"
+			errorMsg = result.Error
+			counts.cancelled++
+		case !result.Success:
 			status = "failed"
 			errorMsg = result.Error
-			failureCount++
+			counts.failed++
+		}
+		if len(result.Items) == 0 {
+			data = append(data, []any{result.AccountId, result.CredentialType, "", status, "", errorMsg})
+			continue
+		}
+		for _, item := range result.Items {
+			switch item.Verification {
+			case rootmanager.VerificationFailed:
+				counts.unverified++
+			case rootmanager.VerificationUnknown:
+				counts.unknown++
+			}
+			itemError := item.Error
+			if itemError == "" {
+				itemError = item.VerificationError
+			}
+			credential := rootmanager.CredentialItem{CredentialType: item.CredentialType, CredentialId: item.CredentialId}
+			data = append(data, []any{result.AccountId, item.CredentialType, credentialLabel(credential), itemStatus(item), item.Verification, itemError})
 		}
-		data = append(data, []any{
-			result.AccountId,
-			result.CredentialType,
-			status,
-			errorMsg,
-		})
 	}
-	output.HandleOutput(w, outputFlag, headers, data)
+	return data, counts
+}
+
+// itemStatus describes the outcome of deleting a root credential.
+func itemStatus(item rootmanager.CredentialItemResult) string {
+	switch {
+	case !item.Success:
+		return "failed"
+	case item.CredentialType == rootmanager.CheckMFA:
+		return "deactivated"
+	default:
+		return "deleted"
+	}
+}
 
-	if failureCount > 0 {
-		return fmt.Errorf("deletion failed for %d account(s)", failureCount)
+// runDeletePlan audits the accounts and lists the root credentials that would
+// be deleted, without making any changes. With planOut, the plan is also saved
+// to that file so that it can be applied later with "delete apply".
+func runDeletePlan(ctx context.Context, rm rootmanager.RootManager, w, errW io.Writer, accountIds []string, credentialType, planOut string) error {
+	progressCtx, stopProgress := withProgress(ctx)
+	audit, err := rm.AuditAccounts(progressCtx, accountIds)
+	stopProgress()
+	if err != nil {
+		return err
 	}
+	createdAt := time.Now()
 
-	return nil
+	plans := rootmanager.PlanDeletion(audit, credentialType)
+	data, unknownCount := planRows(plans, credentialType)
+	output.HandleOutput(w, outputFlag, planHeaders, data)
+
+	var errs []error
+	if planOut != "" {
+		plan := rootmanager.Plan{CreatedAt: createdAt, CredentialType: credentialType, Accounts: plans}
+		if err := rootmanager.SavePlan(planOut, plan); err != nil {
+			errs = append(errs, err)
+		} else {
+			fmt.Fprintf(errW, "Saved deletion plan to %s. Apply it with: aws-root-manager delete apply %s\n", planOut, planOut)
+		}
+	} else {
+		fmt.Fprintln(errW, "Dry run: no changes were made.")
+	}
+
+	if unknownCount > 0 {
+		errs = append(errs, fmt.Errorf("credentials to delete unknown for %d account(s)", unknownCount))
+	}
+	return errors.Join(errs...)
+}
+
+// planHeaders are the output headers of deletion plans.
+var planHeaders = []string{"Account", "CredentialType", "Credential", "Action", "Error"}
+
+// planRows builds the output rows of deletion plans, one per credential to
+// delete, and counts the accounts whose credentials are unknown.
+func planRows(plans []rootmanager.DeletionPlan, credentialType string) (data [][]any, unknownCount int) {
+	for _, plan := range plans {
+		switch {
+		case plan.Error != "":
+			unknownCount++
+			data = append(data, []any{plan.AccountId, credentialType, "", "unknown", plan.Error})
+		case len(plan.Items) == 0:
+			data = append(data, []any{plan.AccountId, credentialType, "", "nothing to delete", ""})
+		}
+		for _, item := range plan.Items {
+			data = append(data, []any{plan.AccountId, item.CredentialType, credentialLabel(item), plannedAction(item), ""})
+		}
+	}
+	return data, unknownCount
+}
+
+// credentialLabel describes a root credential: its ID, or "login profile".
+func credentialLabel(item rootmanager.CredentialItem) string {
+	if item.CredentialType == rootmanager.CheckLogin {
+		return "login profile"
+	}
+	return item.CredentialId
+}
+
+// plannedAction describes what deleting a root credential does to it.
+func plannedAction(item rootmanager.CredentialItem) string {
+	if item.CredentialType == rootmanager.CheckMFA {
+		return "would deactivate"
+	}
+	return "would delete"
 }
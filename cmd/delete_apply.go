@@ -0,0 +1,159 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/unicrons/aws-root-manager/internal/cli/output"
+	"github.com/unicrons/aws-root-manager/internal/cli/ui"
+	"github.com/unicrons/aws-root-manager/rootmanager"
+
+	"github.com/spf13/cobra"
+)
+
+func DeleteApply(newRM func(context.Context) (rootmanager.RootManager, error)) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "apply PLAN_FILE",
+		Short: "Delete the root credentials of a saved plan",
+		Long: `Delete the root credentials listed in a plan saved with "delete <type> --plan-out".
+The planned accounts are audited again first. If the credentials of an account changed since the plan was
+made, the plan is refused; with --allow-stale those accounts are skipped and the rest of the plan is applied.`,
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runDeleteApply(newRM, cmd.OutOrStdout(), cmd.ErrOrStderr(), args[0])
+		},
+	}
+	cmd.Flags().BoolVar(&allowStaleFlag, "allow-stale", false, "Skip the accounts whose credentials changed since the plan was made instead of refusing the plan")
+	return cmd
+}
+
+func runDeleteApply(newRM func(context.Context) (rootmanager.RootManager, error), w, errW io.Writer, path string) error {
+	ctx, cancel := commandContext()
+	defer cancel()
+
+	plan, err := rootmanager.LoadPlan(path)
+	if err != nil {
+		return err
+	}
+
+	rm, err := newRM(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to initialize root manager: %w", err)
+	}
+
+	// accounts whose credentials were unknown or that had nothing to delete are not part of the plan
+	var planned []rootmanager.DeletionPlan
+	var accountIds []string
+	for _, account := range plan.Accounts {
+		if account.Error == "" && len(account.Items) > 0 {
+			planned = append(planned, account)
+			accountIds = append(accountIds, account.AccountId)
+		}
+	}
+	if len(planned) == 0 {
+		fmt.Fprintln(w, "Nothing to delete in the plan.")
+		return nil
+	}
+	slog.Debug("applying deletion plan", "path", path, "created_at", plan.CreatedAt, "accounts", strings.Join(accountIds, ", "))
+
+	progressCtx, stopProgress := withProgress(ctx)
+	audit, err := rm.AuditAccounts(progressCtx, accountIds)
+	stopProgress()
+	if err != nil {
+		return err
+	}
+	current := rootmanager.PlanDeletion(audit, plan.CredentialType)
+
+	// only accounts whose credentials still match the plan are deleted
+	var creds []rootmanager.RootCredentials
+	var applicable []rootmanager.DeletionPlan
+	var skipped []skippedAccount
+	var staleCount, cancelledCount, itemCount int
+	for i, account := range planned {
+		if audit[i].Cancelled {
+			cancelledCount++
+			skipped = append(skipped, skippedAccount{account.AccountId, "cancelled", audit[i].Error})
+			continue
+		}
+		if changes := account.Changes(current[i]); len(changes) > 0 {
+			staleCount++
+			skipped = append(skipped, skippedAccount{account.AccountId, "stale", strings.Join(changes, "; ")})
+			continue
+		}
+		creds = append(creds, audit[i])
+		applicable = append(applicable, current[i])
+		itemCount += len(account.Items)
+	}
+
+	if staleCount > 0 && !allowStaleFlag {
+		output.HandleOutput(w, outputFlag, deletionHeaders, skippedRows(skipped, plan.CredentialType, false))
+		return fmt.Errorf("deletion plan created %s is stale for %d account(s): create a new plan, or use --allow-stale to skip these accounts",
+			plan.CreatedAt.Format(time.RFC3339), staleCount)
+	}
+
+	if dryRunFlag {
+		data, _ := planRows(applicable, plan.CredentialType)
+		output.HandleOutput(w, outputFlag, planHeaders, append(data, skippedRows(skipped, plan.CredentialType, true)...))
+		fmt.Fprintln(errW, "Dry run: no changes were made.")
+		return nil
+	}
+
+	var results []rootmanager.DeletionResult
+	if len(creds) > 0 {
+		if !skipFlag {
+			confirmed, err := ui.Confirm(fmt.Sprintf("Apply deletion plan: delete %d root credential(s) in %d account(s)?", itemCount, len(creds)))
+			if err != nil {
+				return err
+			}
+			if !confirmed {
+				fmt.Fprintln(w, "Aborted.")
+				return nil
+			}
+		}
+
+		progressCtx, stopProgress = withProgress(ctx)
+		results, err = rm.DeleteCredentials(progressCtx, creds, plan.CredentialType)
+		stopProgress()
+		if err != nil {
+			return err
+		}
+		results = verifyDeletion(ctx, rm, results)
+	}
+
+	data, counts := deletionRows(results)
+	output.HandleOutput(w, outputFlag, deletionHeaders, append(data, skippedRows(skipped, plan.CredentialType, false)...))
+
+	counts.cancelled += cancelledCount
+	errs := counts.errs()
+	if staleCount > 0 {
+		errs = append(errs, fmt.Errorf("deletion skipped for %d account(s) whose credentials changed since the plan was made", staleCount))
+	}
+	return errors.Join(errs...)
+}
+
+// skippedAccount is a planned account that is not deleted when applying the plan.
+type skippedAccount struct {
+	accountId string
+	status    string
+	reason    string
+}
+
+// skippedRows builds the output rows of the skipped accounts, with planHeaders
+// when forPlan is set or deletionHeaders otherwise.
+func skippedRows(skipped []skippedAccount, credentialType string, forPlan bool) [][]any {
+	data := make([][]any, len(skipped))
+	for i, account := range skipped {
+		if forPlan {
+			data[i] = []any{account.accountId, credentialType, "", account.status, account.reason}
+		} else {
+			data[i] = []any{account.accountId, credentialType, "", account.status, "", account.reason}
+		}
+	}
+	return data
+}
@@ -0,0 +1,152 @@
+package cmd
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/unicrons/aws-root-manager/rootmanager"
+)
+
+// writePlan saves a deletion plan of access keys to a temporary file and returns its path.
+func writePlan(t *testing.T, accounts ...rootmanager.DeletionPlan) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "plan.json")
+	require.NoError(t, rootmanager.SavePlan(path, rootmanager.Plan{CredentialType: "keys", Accounts: accounts}))
+	return path
+}
+
+func keyPlan(accountId string, keyIds ...string) rootmanager.DeletionPlan {
+	plan := rootmanager.DeletionPlan{AccountId: accountId}
+	for _, id := range keyIds {
+		plan.Items = append(plan.Items, rootmanager.CredentialItem{CredentialType: rootmanager.CheckKeys, CredentialId: id})
+	}
+	return plan
+}
+
+func keyCreds(accountId string, keyIds ...string) rootmanager.RootCredentials {
+	creds := rootmanager.RootCredentials{AccountId: accountId}
+	for _, id := range keyIds {
+		creds.AccessKeys = append(creds.AccessKeys, rootmanager.AccessKey{AccessKeyId: id})
+	}
+	return creds
+}
+
+func keysDeleted(accountId string, keyIds ...string) rootmanager.DeletionResult {
+	result := rootmanager.DeletionResult{AccountId: accountId, CredentialType: rootmanager.CheckKeys, Success: true}
+	for _, id := range keyIds {
+		result.Items = append(result.Items, rootmanager.CredentialItemResult{CredentialType: rootmanager.CheckKeys, CredentialId: id, Success: true})
+	}
+	return result
+}
+
+func TestDeleteCommand_PlanOut(t *testing.T) {
+	setOutputFlag(t, "table")
+	mock := &mockRootManager{
+		auditResult: []rootmanager.RootCredentials{keyCreds("123456789012", "AKIA1"), keyCreds("234567890123")},
+	}
+	path := filepath.Join(t.TempDir(), "plan.json")
+
+	cmd := Delete(newMockFactory(mock))
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetArgs([]string{"keys", "--accounts", "123456789012,234567890123", "--plan-out", path})
+
+	require.NoError(t, cmd.Execute())
+	assert.Zero(t, mock.deleteCalls)
+
+	plan, err := rootmanager.LoadPlan(path)
+	require.NoError(t, err)
+	assert.Equal(t, "keys", plan.CredentialType)
+	assert.Equal(t, []rootmanager.DeletionPlan{keyPlan("123456789012", "AKIA1"), keyPlan("234567890123")}, plan.Accounts)
+}
+
+func TestDeleteApplyCommand_Success(t *testing.T) {
+	setOutputFlag(t, "table")
+	path := writePlan(t, keyPlan("123456789012", "AKIA1"), keyPlan("234567890123"))
+	mock := &mockRootManager{
+		auditResult:  []rootmanager.RootCredentials{keyCreds("123456789012", "AKIA1")},
+		deleteResult: []rootmanager.DeletionResult{keysDeleted("123456789012", "AKIA1")},
+	}
+
+	var buf bytes.Buffer
+	cmd := Delete(newMockFactory(mock))
+	cmd.SetOut(&buf)
+	cmd.SetArgs([]string{"apply", path, "--yes"})
+
+	require.NoError(t, cmd.Execute())
+	assert.Equal(t, 1, mock.deleteCalls)
+	assert.Equal(t, []rootmanager.RootCredentials{keyCreds("123456789012", "AKIA1")}, mock.deletedCreds)
+	assert.Contains(t, buf.String(), "deleted")
+}
+
+func TestDeleteApplyCommand_StalePlanRefused(t *testing.T) {
+	setOutputFlag(t, "csv")
+	path := writePlan(t, keyPlan("123456789012", "AKIA1"), keyPlan("234567890123", "AKIA2"))
+	mock := &mockRootManager{
+		auditResult: []rootmanager.RootCredentials{keyCreds("123456789012", "AKIA1"), keyCreds("234567890123", "AKIA2", "AKIA3")},
+	}
+
+	var buf bytes.Buffer
+	cmd := Delete(newMockFactory(mock))
+	cmd.SetOut(&buf)
+	cmd.SetArgs([]string{"apply", path, "--yes"})
+
+	err := cmd.Execute()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "stale for 1 account(s)")
+	assert.Zero(t, mock.deleteCalls)
+	assert.Contains(t, buf.String(), "234567890123,keys,,stale,,keys AKIA3 appeared")
+}
+
+func TestDeleteApplyCommand_AllowStale(t *testing.T) {
+	setOutputFlag(t, "csv")
+	path := writePlan(t, keyPlan("123456789012", "AKIA1"), keyPlan("234567890123", "AKIA2"))
+	mock := &mockRootManager{
+		auditResult:  []rootmanager.RootCredentials{keyCreds("123456789012", "AKIA1"), keyCreds("234567890123")},
+		deleteResult: []rootmanager.DeletionResult{keysDeleted("123456789012", "AKIA1")},
+	}
+
+	var buf bytes.Buffer
+	cmd := Delete(newMockFactory(mock))
+	cmd.SetOut(&buf)
+	cmd.SetArgs([]string{"apply", path, "--yes", "--allow-stale"})
+
+	err := cmd.Execute()
+	require.EqualError(t, err, "deletion skipped for 1 account(s) whose credentials changed since the plan was made")
+	assert.Equal(t, []rootmanager.RootCredentials{keyCreds("123456789012", "AKIA1")}, mock.deletedCreds)
+	assert.Contains(t, buf.String(), "123456789012,keys,AKIA1,deleted,verified,")
+	assert.Contains(t, buf.String(), "234567890123,keys,,stale,,keys AKIA2 disappeared")
+}
+
+func TestDeleteApplyCommand_DryRun(t *testing.T) {
+	setOutputFlag(t, "csv")
+	path := writePlan(t, keyPlan("123456789012", "AKIA1"))
+	mock := &mockRootManager{
+		auditResult: []rootmanager.RootCredentials{keyCreds("123456789012", "AKIA1")},
+	}
+
+	var buf, errBuf bytes.Buffer
+	cmd := Delete(newMockFactory(mock))
+	cmd.SetOut(&buf)
+	cmd.SetErr(&errBuf)
+	cmd.SetArgs([]string{"apply", path, "--dry-run"})
+
+	require.NoError(t, cmd.Execute())
+	assert.Zero(t, mock.deleteCalls)
+	assert.Contains(t, buf.String(), "123456789012,keys,AKIA1,would delete,")
+	assert.Equal(t, "Dry run: no changes were made.\n", errBuf.String())
+}
+
+func TestDeleteApplyCommand_InvalidPlan(t *testing.T) {
+	mock := &mockRootManager{}
+	cmd := Delete(newMockFactory(mock))
+	cmd.SilenceErrors = true
+	cmd.SetArgs([]string{"apply", filepath.Join(t.TempDir(), "missing.json"), "--yes"})
+
+	err := cmd.Execute()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to read deletion plan")
+	assert.Zero(t, mock.deleteCalls)
+}
@@ -0,0 +1,156 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/unicrons/aws-root-manager/internal/cli/output"
+	"github.com/unicrons/aws-root-manager/internal/cli/ui"
+	"github.com/unicrons/aws-root-manager/rootmanager"
+)
+
+// accountItem is a root credential of an account.
+type accountItem struct {
+	accountId string
+	item      rootmanager.CredentialItem
+}
+
+// runDeleteItems audits the accounts and deletes the credentials selected by
+// ID with itemIdsFlag or interactively with pickItemsFlag, keeping the others.
+func runDeleteItems(ctx context.Context, rm rootmanager.RootManager, w, errW io.Writer, accountIds []string, credentialType string) error {
+	progressCtx, stopProgress := withProgress(ctx)
+	audit, err := rm.AuditAccounts(progressCtx, accountIds)
+	stopProgress()
+	if err != nil {
+		return err
+	}
+
+	var candidates []accountItem
+	var unknown []string
+	for _, plan := range rootmanager.PlanDeletion(audit, credentialType) {
+		if plan.Error != "" {
+			unknown = append(unknown, plan.AccountId)
+			fmt.Fprintf(errW, "Skipping account %s: %s\n", plan.AccountId, plan.Error)
+			continue
+		}
+		for _, item := range plan.Items {
+			candidates = append(candidates, accountItem{plan.AccountId, item})
+		}
+	}
+
+	selected, err := selectItems(candidates, unknown)
+	if err != nil {
+		return err
+	}
+	if len(selected) == 0 {
+		fmt.Fprintln(w, "No root credentials selected.")
+		return nil
+	}
+	requests := deletionRequests(selected)
+
+	if dryRunFlag {
+		plans := make([]rootmanager.DeletionPlan, len(requests))
+		for i, request := range requests {
+			plans[i] = rootmanager.DeletionPlan{AccountId: request.AccountId, Items: request.Items}
+		}
+		data, _ := planRows(plans, credentialType)
+		output.HandleOutput(w, outputFlag, planHeaders, data)
+		fmt.Fprintln(errW, "Dry run: no changes were made.")
+		return nil
+	}
+
+	if !skipFlag {
+		confirmed, err := ui.Confirm(fmt.Sprintf("Delete %d root credential(s) in %d account(s)?", len(selected), len(requests)))
+		if err != nil {
+			return err
+		}
+		if !confirmed {
+			fmt.Fprintln(w, "Aborted.")
+			return nil
+		}
+	}
+
+	progressCtx, stopProgress = withProgress(ctx)
+	results, err := rm.DeleteCredentialItems(progressCtx, requests)
+	stopProgress()
+	if err != nil {
+		return err
+	}
+	results = verifyDeletion(ctx, rm, results)
+
+	data, counts := deletionRows(results)
+	output.HandleOutput(w, outputFlag, deletionHeaders, data)
+
+	errs := counts.errs()
+	if len(unknown) > 0 {
+		errs = append(errs, fmt.Errorf("deletion skipped for %d account(s) whose credentials are unknown", len(unknown)))
+	}
+	return errors.Join(errs...)
+}
+
+// selectItems returns the candidates whose ID is in itemIdsFlag, then prompts
+// for the ones to delete when pickItemsFlag is set. Every requested ID must
+// match a candidate; unknown lists the accounts whose credentials are unknown.
+func selectItems(candidates []accountItem, unknown []string) ([]accountItem, error) {
+	selected := candidates
+	if len(itemIdsFlag) > 0 {
+		selected = nil
+		var missing []string
+		for _, id := range itemIdsFlag {
+			matches := slices.DeleteFunc(slices.Clone(candidates), func(candidate accountItem) bool {
+				return candidate.item.CredentialId != id
+			})
+			if len(matches) == 0 {
+				missing = append(missing, id)
+			}
+			selected = append(selected, matches...)
+		}
+		if len(missing) > 0 {
+			err := fmt.Errorf("root credential(s) not found in the selected accounts: %s", strings.Join(missing, ", "))
+			if len(unknown) > 0 {
+				err = fmt.Errorf("%w (credentials unknown for %s)", err, strings.Join(unknown, ", "))
+			}
+			return nil, err
+		}
+	}
+
+	if !pickItemsFlag || len(selected) == 0 {
+		return selected, nil
+	}
+	choices := make([]string, len(selected))
+	for i, candidate := range selected {
+		choices[i] = fmt.Sprintf("%s - %s", candidate.accountId, candidate.item)
+	}
+	indexes, err := ui.Prompt("Please select the root credentials to delete", choices)
+	if err != nil {
+		return nil, err
+	}
+	picked := make([]accountItem, len(indexes))
+	for i, idx := range indexes {
+		picked[i] = selected[idx]
+	}
+	return picked, nil
+}
+
+// deletionRequests groups the credentials to delete by account, keeping the
+// order of the accounts.
+func deletionRequests(items []accountItem) []rootmanager.DeletionRequest {
+	var requests []rootmanager.DeletionRequest
+	for _, selected := range items {
+		idx := slices.IndexFunc(requests, func(request rootmanager.DeletionRequest) bool {
+			return request.AccountId == selected.accountId
+		})
+		if idx < 0 {
+			requests = append(requests, rootmanager.DeletionRequest{AccountId: selected.accountId})
+			idx = len(requests) - 1
+		}
+		if !slices.Contains(requests[idx].Items, selected.item) {
+			requests[idx].Items = append(requests[idx].Items, selected.item)
+		}
+	}
+	return requests
+}
@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/unicrons/aws-root-manager/rootmanager"
+)
+
+func TestDeleteCommand_KeyIds(t *testing.T) {
+	setOutputFlag(t, "table")
+	mock := &mockRootManager{
+		auditResult: []rootmanager.RootCredentials{
+			keyCreds("123456789012", "AKIALEAKED", "AKIAVENDOR"),
+			keyCreds("234567890123", "AKIAOTHER"),
+		},
+		deleteResult: []rootmanager.DeletionResult{keysDeleted("123456789012", "AKIALEAKED")},
+	}
+
+	var buf bytes.Buffer
+	cmd := Delete(newMockFactory(mock))
+	cmd.SetOut(&buf)
+	cmd.SetArgs([]string{"keys", "--accounts", "123456789012,234567890123", "--key-id", "AKIALEAKED", "--yes"})
+
+	require.NoError(t, cmd.Execute())
+	assert.Equal(t, []rootmanager.DeletionRequest{
+		{AccountId: "123456789012", Items: []rootmanager.CredentialItem{{CredentialType: rootmanager.CheckKeys, CredentialId: "AKIALEAKED"}}},
+	}, mock.deleteRequests)
+	assert.Contains(t, buf.String(), "deleted")
+}
+
+func TestDeleteCommand_KeyIdNotFound(t *testing.T) {
+	mock := &mockRootManager{
+		auditResult: []rootmanager.RootCredentials{
+			keyCreds("123456789012", "AKIAVENDOR"),
+			{AccountId: "234567890123", Error: "access denied"},
+		},
+	}
+
+	var errBuf bytes.Buffer
+	cmd := Delete(newMockFactory(mock))
+	cmd.SilenceErrors = true
+	cmd.SetErr(&errBuf)
+	cmd.SetArgs([]string{"keys", "--accounts", "123456789012,234567890123", "--key-id", "AKIAVENDOR,AKIAMISSING", "--yes"})
+
+	err := cmd.Execute()
+	require.EqualError(t, err, "root credential(s) not found in the selected accounts: AKIAMISSING (credentials unknown for 234567890123)")
+	assert.Zero(t, mock.deleteCalls)
+	assert.Contains(t, errBuf.String(), "Skipping account 234567890123: account audit failed: access denied")
+}
+
+func TestDeleteCommand_MFASerialDryRun(t *testing.T) {
+	setOutputFlag(t, "csv")
+	mock := &mockRootManager{
+		auditResult: []rootmanager.RootCredentials{
+			{AccountId: "123456789012", MfaDevices: []rootmanager.MFADevice{{SerialNumber: "mfa-1"}, {SerialNumber: "mfa-2"}}},
+		},
+	}
+
+	var buf, errBuf bytes.Buffer
+	cmd := Delete(newMockFactory(mock))
+	cmd.SetOut(&buf)
+	cmd.SetErr(&errBuf)
+	cmd.SetArgs([]string{"mfa", "--accounts", "123456789012", "--mfa-serial", "mfa-2", "--dry-run"})
+
+	require.NoError(t, cmd.Execute())
+	assert.Zero(t, mock.deleteCalls)
+	assert.Equal(t, "Dry run: no changes were made.\n", errBuf.String())
+	assert.Contains(t, buf.String(), "123456789012,mfa,mfa-2,would deactivate,")
+	assert.NotContains(t, buf.String(), "mfa-1")
+}
+
+func TestDeleteCommand_ItemsWithPlanOut(t *testing.T) {
+	mock := &mockRootManager{}
+	cmd := Delete(newMockFactory(mock))
+	cmd.SilenceErrors = true
+	cmd.SetArgs([]string{"certificates", "--accounts", "123456789012", "--certificate-id", "cert-1", "--plan-out", "plan.json"})
+
+	require.EqualError(t, cmd.Execute(), "--plan-out cannot be combined with credential IDs or --pick")
+}
+
+func TestDeletionRequests(t *testing.T) {
+	key := rootmanager.CredentialItem{CredentialType: rootmanager.CheckKeys, CredentialId: "AKIA1"}
+	cert := rootmanager.CredentialItem{CredentialType: rootmanager.CheckCertificates, CredentialId: "cert-1"}
+
+	requests := deletionRequests([]accountItem{
+		{"234567890123", key},
+		{"123456789012", cert},
+		{"234567890123", cert},
+		{"234567890123", key},
+	})
+	assert.Equal(t, []rootmanager.DeletionRequest{
+		{AccountId: "234567890123", Items: []rootmanager.CredentialItem{key, cert}},
+		{AccountId: "123456789012", Items: []rootmanager.CredentialItem{cert}},
+	}, requests)
+}
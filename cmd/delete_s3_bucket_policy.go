@@ -8,7 +8,6 @@ import (
 	"log/slog"
 
 	"github.com/spf13/cobra"
-	"github.com/unicrons/aws-root-manager/internal/aws"
 	"github.com/unicrons/aws-root-manager/internal/cli/output"
 	"github.com/unicrons/aws-root-manager/internal/cli/ui"
 	"github.com/unicrons/aws-root-manager/rootmanager"
@@ -31,7 +30,9 @@ func DeleteS3BucketPolicy(newRM func(context.Context) (rootmanager.RootManager,
 }
 
 func runDeleteS3BucketPolicy(newRM func(context.Context) (rootmanager.RootManager, error), w io.Writer, accountId, bucketName string) error {
-	ctx := context.Background()
+	ctx, cancel := commandContext()
+	defer cancel()
+
 	rm, err := newRM(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to initialize root manager: %w", err)
@@ -73,50 +74,63 @@ func runDeleteS3BucketPolicy(newRM func(context.Context) (rootmanager.RootManage
 		output.RenderPolicy(w, policy)
 	}
 
-	if !skipFlag {
-		confirmed, err := ui.Confirm("Delete this policy?")
+	status := "deleted"
+	var result rootmanager.PolicyDeletionResult
+	if dryRunFlag {
+		status = "would delete"
+		result = rootmanager.PolicyDeletionResult{AccountId: accountId, ResourceType: rootmanager.ResourceTypeS3Bucket, ResourceName: bucketName}
+	} else {
+		if !skipFlag {
+			confirmed, err := ui.Confirm("Delete this policy?")
+			if err != nil {
+				return err
+			}
+			if !confirmed {
+				fmt.Fprintln(w, "Aborted.")
+				return nil
+			}
+		}
+
+		result, err = rm.DeleteS3BucketPolicy(ctx, accountId, bucketName)
 		if err != nil {
 			return err
 		}
-		if !confirmed {
-			fmt.Fprintln(w, "Aborted.")
-			return nil
+		if !result.Success {
+			slog.Error("failed to delete s3 bucket policy", "account_id", result.AccountId, "bucket", result.ResourceName, "error", result.Error)
+			return fmt.Errorf("failed to delete bucket policy for bucket %s", result.ResourceName)
 		}
 	}
 
-	result, err := rm.DeleteS3BucketPolicy(ctx, accountId, bucketName)
-	if err != nil {
-		return err
-	}
-
-	if !result.Success {
-		slog.Error("failed to delete s3 bucket policy", "account_id", result.AccountId, "bucket", result.ResourceName, "error", result.Error)
-		return fmt.Errorf("failed to delete bucket policy for bucket %s", result.ResourceName)
-	}
-
 	var headers []string
 	var data [][]any
 	if outputFlag == "table" {
 		headers = []string{"Account", "ResourceType", "Bucket", "Status"}
-		data = [][]any{{result.AccountId, result.ResourceType, result.ResourceName, "deleted"}}
+		data = [][]any{{result.AccountId, result.ResourceType, result.ResourceName, status}}
 	} else {
 		headers = []string{"Account", "ResourceType", "Bucket", "Status", "Policy"}
-		data = [][]any{{result.AccountId, result.ResourceType, result.ResourceName, "deleted", json.RawMessage(policy)}}
+		data = [][]any{{result.AccountId, result.ResourceType, result.ResourceName, status, json.RawMessage(policy)}}
 	}
 	output.HandleOutput(w, outputFlag, headers, data)
 	return nil
 }
 
 // selectSingleAccount resolves a single account ID from the --account flag or
-// via a single-select TUI (no "all" option).
+// via a single-select TUI (no "all" option). Accounts that are not active are rejected.
 func selectSingleAccount(ctx context.Context, accountId string) (string, error) {
 	var flag []string
 	if accountId != "" {
 		flag = []string{accountId}
 	}
-	awscfg, err := aws.LoadAWSConfig(ctx)
+	_, orgAccounts, err := organizationAccounts(ctx)
 	if err != nil {
-		return "", fmt.Errorf("failed to load aws config: %w", err)
+		return "", err
+	}
+	selected, err := ui.SelectSingleTargetAccount(orgAccounts, flag)
+	if err != nil {
+		return "", err
+	}
+	if status, ok := inactiveAccounts(orgAccounts, []string{selected})[selected]; ok {
+		return "", fmt.Errorf("account %s is not active (%s) and cannot be changed", selected, status)
 	}
-	return ui.SelectSingleTargetAccount(ctx, aws.NewOrganizationsClient(awscfg), flag)
+	return selected, nil
 }
@@ -88,3 +88,32 @@ func TestDeleteS3BucketPolicyCommand_ListBucketsError(t *testing.T) {
 
 	require.Error(t, cmd.Execute())
 }
+
+func TestDeleteS3BucketPolicyCommand_DryRun(t *testing.T) {
+	setOutputFlag(t, "table")
+	mock := &mockRootManager{
+		getBucketPolicyResult: `{"Version":"2012-10-17","Statement":[]}`,
+		deleteBucketErr:       errors.New("should not be called"),
+	}
+
+	var buf bytes.Buffer
+	cmd := Delete(newMockFactory(mock))
+	cmd.SetOut(&buf)
+	cmd.SetArgs([]string{"s3-bucket-policy", "--account", "123456789012", "--bucket", "my-bucket", "--dry-run"})
+
+	require.NoError(t, cmd.Execute())
+	assert.Contains(t, buf.String(), "would delete")
+}
+
+func TestDeleteS3BucketPolicyCommand_InactiveAccount(t *testing.T) {
+	setOrganizationsClient(t, newStatusesMock())
+	mock := &mockRootManager{}
+
+	cmd := DeleteS3BucketPolicy(newMockFactory(mock))
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetArgs([]string{"--account", "234567890123", "--bucket", "my-bucket"})
+
+	err := cmd.Execute()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "account 234567890123 is not active (SUSPENDED) and cannot be changed")
+}
@@ -30,7 +30,9 @@ func DeleteSQSQueuePolicy(newRM func(context.Context) (rootmanager.RootManager,
 }
 
 func runDeleteSQSQueuePolicy(newRM func(context.Context) (rootmanager.RootManager, error), w io.Writer, accountId, queueUrl string) error {
-	ctx := context.Background()
+	ctx, cancel := commandContext()
+	defer cancel()
+
 	rm, err := newRM(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to initialize root manager: %w", err)
@@ -72,35 +74,41 @@ func runDeleteSQSQueuePolicy(newRM func(context.Context) (rootmanager.RootManage
 		output.RenderPolicy(w, policy)
 	}
 
-	if !skipFlag {
-		confirmed, err := ui.Confirm("Delete this policy?")
+	status := "deleted"
+	var result rootmanager.PolicyDeletionResult
+	if dryRunFlag {
+		status = "would delete"
+		result = rootmanager.PolicyDeletionResult{AccountId: accountId, ResourceType: rootmanager.ResourceTypeSQSQueue, ResourceName: queueUrl}
+	} else {
+		if !skipFlag {
+			confirmed, err := ui.Confirm("Delete this policy?")
+			if err != nil {
+				return err
+			}
+			if !confirmed {
+				fmt.Fprintln(w, "Aborted.")
+				return nil
+			}
+		}
+
+		result, err = rm.DeleteSQSQueuePolicy(ctx, accountId, queueUrl)
 		if err != nil {
 			return err
 		}
-		if !confirmed {
-			fmt.Fprintln(w, "Aborted.")
-			return nil
+		if !result.Success {
+			slog.Error("failed to delete sqs queue policy", "account_id", result.AccountId, "queue_url", result.ResourceName, "error", result.Error)
+			return fmt.Errorf("failed to delete queue policy for queue %s", result.ResourceName)
 		}
 	}
 
-	result, err := rm.DeleteSQSQueuePolicy(ctx, accountId, queueUrl)
-	if err != nil {
-		return err
-	}
-
-	if !result.Success {
-		slog.Error("failed to delete sqs queue policy", "account_id", result.AccountId, "queue_url", result.ResourceName, "error", result.Error)
-		return fmt.Errorf("failed to delete queue policy for queue %s", result.ResourceName)
-	}
-
 	var headers []string
 	var data [][]any
 	if outputFlag == "table" {
 		headers = []string{"Account", "ResourceType", "Queue", "Status"}
-		data = [][]any{{result.AccountId, result.ResourceType, result.ResourceName, "deleted"}}
+		data = [][]any{{result.AccountId, result.ResourceType, result.ResourceName, status}}
 	} else {
 		headers = []string{"Account", "ResourceType", "Queue", "Status", "Policy"}
-		data = [][]any{{result.AccountId, result.ResourceType, result.ResourceName, "deleted", json.RawMessage(policy)}}
+		data = [][]any{{result.AccountId, result.ResourceType, result.ResourceName, status, json.RawMessage(policy)}}
 	}
 	output.HandleOutput(w, outputFlag, headers, data)
 	return nil
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -76,3 +77,273 @@ func TestDeleteCommand_DeleteFailure(t *testing.T) {
 
 	require.Error(t, cmd.Execute())
 }
+
+func TestDeleteCommand_CancelledAccounts(t *testing.T) {
+	setOutputFlag(t, "csv")
+	mock := &mockRootManager{
+		auditResult: []rootmanager.RootCredentials{{AccountId: "111111111111"}, {AccountId: "222222222222"}},
+		deleteResult: []rootmanager.DeletionResult{
+			{AccountId: "111111111111", CredentialType: "all", Success: true},
+			{AccountId: "222222222222", CredentialType: "all", Cancelled: true, Error: "not processed: timeout of 1m0s exceeded"},
+		},
+	}
+
+	var buf bytes.Buffer
+	cmd := Delete(newMockFactory(mock))
+	cmd.SilenceErrors = true
+	cmd.SetOut(&buf)
+	cmd.SetArgs([]string{"all", "--accounts", "111111111111,222222222222", "--yes"})
+
+	err := cmd.Execute()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "deletion cancelled for 1 account(s)")
+	assert.Contains(t, buf.String(), "111111111111,all,,nothing to delete,,")
+	assert.Contains(t, buf.String(), "222222222222,all,,cancelled,,not processed: timeout of 1m0s exceeded")
+}
+
+func TestDeleteCommand_DryRun(t *testing.T) {
+	setOutputFlag(t, "csv")
+	mock := &mockRootManager{
+		auditResult: []rootmanager.RootCredentials{
+			{
+				AccountId:    "123456789012",
+				LoginProfile: true,
+				AccessKeys:   []rootmanager.AccessKey{{AccessKeyId: "AKIAEXAMPLE"}},
+				MfaDevices:   []rootmanager.MFADevice{{SerialNumber: "arn:aws:iam::123456789012:mfa/root"}},
+			},
+			{AccountId: "234567890123"},
+		},
+	}
+
+	var buf, errBuf bytes.Buffer
+	cmd := Delete(newMockFactory(mock))
+	cmd.SetOut(&buf)
+	cmd.SetErr(&errBuf)
+	cmd.SetArgs([]string{"all", "--accounts", "123456789012,234567890123", "--dry-run"})
+
+	require.NoError(t, cmd.Execute())
+	assert.Zero(t, mock.deleteCalls)
+	assert.Equal(t, "Dry run: no changes were made.\n", errBuf.String())
+	out := buf.String()
+	assert.Contains(t, out, "123456789012,login,login profile,would delete,")
+	assert.Contains(t, out, "123456789012,keys,AKIAEXAMPLE,would delete,")
+	assert.Contains(t, out, "123456789012,mfa,arn:aws:iam::123456789012:mfa/root,would deactivate,")
+	assert.Contains(t, out, "234567890123,all,,nothing to delete,")
+}
+
+func TestDeleteCommand_DryRunAuditFailure(t *testing.T) {
+	setOutputFlag(t, "csv")
+	mock := &mockRootManager{
+		auditResult: []rootmanager.RootCredentials{
+			{AccountId: "123456789012", Error: "access denied"},
+		},
+	}
+
+	var buf bytes.Buffer
+	cmd := Delete(newMockFactory(mock))
+	cmd.SetOut(&buf)
+	cmd.SetArgs([]string{"keys", "--accounts", "123456789012", "--dry-run"})
+
+	err := cmd.Execute()
+	require.EqualError(t, err, "credentials to delete unknown for 1 account(s)")
+	assert.Zero(t, mock.deleteCalls)
+	assert.Contains(t, buf.String(), "123456789012,keys,,unknown,account audit failed: access denied")
+}
+
+func TestDeleteCommand_Types(t *testing.T) {
+	mock := &mockRootManager{
+		auditResult: []rootmanager.RootCredentials{
+			{AccountId: "123456789012"},
+		},
+		deleteResult: []rootmanager.DeletionResult{
+			{AccountId: "123456789012", CredentialType: "login,keys,certificate", Success: true},
+		},
+	}
+
+	var buf bytes.Buffer
+	cmd := Delete(newMockFactory(mock))
+	cmd.SetOut(&buf)
+	cmd.SetArgs([]string{"all", "--accounts", "123456789012", "--types", "certificates,login", "--types", "keys", "--yes"})
+
+	require.NoError(t, cmd.Execute())
+	assert.Equal(t, 1, mock.deleteCalls)
+	assert.Equal(t, "login,keys,certificate", mock.deletedType)
+}
+
+func TestParseCredentialTypes(t *testing.T) {
+	tests := []struct {
+		name    string
+		types   []string
+		want    string
+		wantErr string
+	}{
+		{"single", []string{"mfa"}, "mfa", ""},
+		{"ordered", []string{"certificates", "Login", "keys", "login"}, "login,keys,certificate", ""},
+		{"every type", []string{"login", "keys", "mfa", "certificate"}, "all", ""},
+		{"invalid", []string{"keys", "passwords"}, "", `invalid credential type "passwords"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseCredentialTypes(tt.types)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestDeleteCommand_ItemResults(t *testing.T) {
+	setOutputFlag(t, "csv")
+	mock := &mockRootManager{
+		auditResult: []rootmanager.RootCredentials{{AccountId: "123456789012"}},
+		deleteResult: []rootmanager.DeletionResult{
+			{AccountId: "123456789012", CredentialType: "all", Error: "failed to delete 1 of 3 root credential(s)", Items: []rootmanager.CredentialItemResult{
+				{CredentialType: rootmanager.CheckLogin, Success: true},
+				{CredentialType: rootmanager.CheckMFA, CredentialId: "mfa-1", Success: true},
+				{CredentialType: rootmanager.CheckCertificates, CredentialId: "cert-1", Error: "access denied"},
+			}},
+		},
+	}
+
+	var buf bytes.Buffer
+	cmd := Delete(newMockFactory(mock))
+	cmd.SilenceErrors = true
+	cmd.SetOut(&buf)
+	cmd.SetArgs([]string{"all", "--accounts", "123456789012", "--yes"})
+
+	require.EqualError(t, cmd.Execute(), "deletion failed for 1 account(s)")
+	out := buf.String()
+	assert.Contains(t, out, "123456789012,login,login profile,deleted,verified,")
+	assert.Contains(t, out, "123456789012,mfa,mfa-1,deactivated,verified,")
+	assert.Contains(t, out, "123456789012,certificate,cert-1,failed,,access denied")
+}
+
+func TestDeleteCommand_Verification(t *testing.T) {
+	setOutputFlag(t, "csv")
+	mock := &mockRootManager{
+		auditResult: []rootmanager.RootCredentials{{AccountId: "123456789012"}, {AccountId: "234567890123"}, {AccountId: "345678901234"}},
+		deleteResult: []rootmanager.DeletionResult{
+			{AccountId: "123456789012", CredentialType: "keys", Success: true, Items: []rootmanager.CredentialItemResult{
+				{CredentialType: rootmanager.CheckKeys, CredentialId: "AKIA1", Success: true},
+				{CredentialType: rootmanager.CheckKeys, CredentialId: "AKIA2", Success: true},
+			}},
+			{AccountId: "234567890123", CredentialType: "keys", Success: true, Items: []rootmanager.CredentialItemResult{
+				{CredentialType: rootmanager.CheckKeys, CredentialId: "AKIA3", Success: true},
+			}},
+			{AccountId: "345678901234", CredentialType: "keys", Success: true},
+		},
+		verifyResults: [][]rootmanager.RootCredentials{{
+			{AccountId: "123456789012", AccessKeys: []rootmanager.AccessKey{{AccessKeyId: "AKIA2"}}},
+			{AccountId: "234567890123", Error: "access denied"},
+		}},
+	}
+	setVerificationBackoff(t, 0, 0)
+
+	var buf bytes.Buffer
+	cmd := Delete(newMockFactory(mock))
+	cmd.SilenceErrors = true
+	cmd.SetOut(&buf)
+	cmd.SetArgs([]string{"keys", "--accounts", "123456789012,234567890123,345678901234", "--yes"})
+
+	err := cmd.Execute()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "verification failed: 1 root credential(s) still present after deletion")
+	assert.Contains(t, err.Error(), "verification incomplete: 1 deleted root credential(s) could not be audited again")
+	// the account whose key is still listed is audited again after each delay
+	assert.Equal(t, 3, mock.verifyCalls)
+	assert.Equal(t, []string{"123456789012"}, mock.verifiedAccounts)
+	out := buf.String()
+	assert.Contains(t, out, "123456789012,keys,AKIA1,deleted,verified,")
+	assert.Contains(t, out, "123456789012,keys,AKIA2,deleted,failed,keys AKIA2 still present after deletion")
+	assert.Contains(t, out, "234567890123,keys,AKIA3,deleted,unknown,account audit failed: access denied")
+	assert.Contains(t, out, "345678901234,keys,,nothing to delete,,")
+}
+
+func TestDeleteCommand_VerificationRetry(t *testing.T) {
+	setOutputFlag(t, "csv")
+	mock := &mockRootManager{
+		auditResult: []rootmanager.RootCredentials{{AccountId: "123456789012"}, {AccountId: "234567890123"}},
+		deleteResult: []rootmanager.DeletionResult{
+			keysDeleted("123456789012", "AKIA1"),
+			keysDeleted("234567890123", "AKIA2"),
+		},
+		verifyResults: [][]rootmanager.RootCredentials{
+			// the deletion of AKIA1 is not visible yet
+			{keyCreds("123456789012", "AKIA1"), keyCreds("234567890123")},
+			{keyCreds("123456789012")},
+		},
+	}
+	setVerificationBackoff(t, time.Millisecond, time.Millisecond)
+
+	var buf bytes.Buffer
+	cmd := Delete(newMockFactory(mock))
+	cmd.SetOut(&buf)
+	cmd.SetArgs([]string{"keys", "--accounts", "123456789012,234567890123", "--yes"})
+
+	require.NoError(t, cmd.Execute())
+	assert.Equal(t, 2, mock.verifyCalls)
+	assert.Equal(t, []string{"123456789012"}, mock.verifiedAccounts)
+	assert.Contains(t, buf.String(), "123456789012,keys,AKIA1,deleted,verified,")
+	assert.Contains(t, buf.String(), "234567890123,keys,AKIA2,deleted,verified,")
+}
+
+func TestDeleteCommand_SkipsInactiveAccounts(t *testing.T) {
+	setOrganizationsClient(t, newStatusesMock())
+	mock := &mockRootManager{
+		auditResult: []rootmanager.RootCredentials{
+			{AccountId: "123456789012", LoginProfile: true},
+		},
+		deleteResult: []rootmanager.DeletionResult{
+			{AccountId: "123456789012", CredentialType: "login", Success: true},
+		},
+	}
+
+	var errBuf bytes.Buffer
+	cmd := Delete(newMockFactory(mock))
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetErr(&errBuf)
+	cmd.SetArgs([]string{"login", "--accounts", "123456789012,234567890123", "--include-status", "suspended", "--yes"})
+
+	require.NoError(t, cmd.Execute())
+	assert.Contains(t, errBuf.String(), "Skipping 1 account(s) that are not active and cannot be changed: 234567890123 (SUSPENDED)")
+	assert.Equal(t, []string{"123456789012"}, mock.auditedAccounts)
+	require.Len(t, mock.deletedCreds, 1)
+	assert.Equal(t, "123456789012", mock.deletedCreds[0].AccountId)
+}
+
+func TestDeleteCommand_OnlyInactiveAccounts(t *testing.T) {
+	setOrganizationsClient(t, newStatusesMock())
+	mock := &mockRootManager{}
+
+	cmd := Delete(newMockFactory(mock))
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetArgs([]string{"login", "--accounts", "234567890123", "--yes"})
+
+	require.NoError(t, cmd.Execute())
+	assert.Nil(t, mock.auditedAccounts)
+	assert.Zero(t, mock.deleteCalls)
+}
+
+func TestDeleteCommand_ListsOrganizationAccountsOnce(t *testing.T) {
+	org := newStatusesMock()
+	setOrganizationsClient(t, org)
+	mock := &mockRootManager{
+		auditResult: []rootmanager.RootCredentials{
+			{AccountId: "123456789012", LoginProfile: true},
+		},
+		deleteResult: []rootmanager.DeletionResult{
+			{AccountId: "123456789012", CredentialType: "login", Success: true},
+		},
+	}
+
+	cmd := Delete(newMockFactory(mock))
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetArgs([]string{"login", "--accounts", "all", "--include-status", "suspended", "--exclude", "^closing$", "--yes"})
+
+	require.NoError(t, cmd.Execute())
+	assert.Equal(t, 1, org.listCalls)
+	assert.Equal(t, []string{"123456789012"}, mock.auditedAccounts)
+}
@@ -21,7 +21,9 @@ func Enable(newRM func(context.Context) (rootmanager.RootManager, error)) *cobra
 
 			enableRootSessions, _ := cmd.Flags().GetBool("enableRootSessions")
 
-			ctx := context.Background()
+			ctx, cancel := commandContext()
+			defer cancel()
+
 			rm, err := newRM(ctx)
 			if err != nil {
 				slog.Error("failed to initialize root manager", "error", err)
@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/unicrons/aws-root-manager/internal/cli/output"
+	"github.com/unicrons/aws-root-manager/rootmanager"
+
+	"github.com/spf13/cobra"
+)
+
+func History() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:          "history ACCOUNT_ID",
+		Short:        "Show root credentials of an account over time",
+		Long:         `Show the root credentials of an account in every audit snapshot that includes it, oldest first.`,
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			slog.Debug("history called")
+
+			if snapshotDirFlag == "" {
+				return errors.New("--snapshot-dir is required")
+			}
+			accountId := args[0]
+
+			history, err := rootmanager.NewSnapshotStore(snapshotDirFlag).AccountHistory(accountId)
+			if err != nil {
+				return err
+			}
+			if len(history) == 0 {
+				return fmt.Errorf("account %s not found in any snapshot in %s", accountId, snapshotDirFlag)
+			}
+
+			headers := []string{"Snapshot", "LoginProfile", "AccessKeys", "MFA Devices", "Signing Certificates"}
+			var data [][]any
+			for _, entry := range history {
+				if entry.Credentials.Error != "" {
+					failed := "audit failed: " + entry.Credentials.Error
+					data = append(data, []any{entry.SnapshotId, failed, failed, failed, failed})
+					continue
+				}
+				data = append(data, auditRow(entry.SnapshotId, entry.Credentials, outputFlag))
+			}
+			output.HandleOutput(cmd.OutOrStdout(), outputFlag, headers, data)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&snapshotDirFlag, "snapshot-dir", "", "Directory where audit snapshots are stored")
+	return cmd
+}
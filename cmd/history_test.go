@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/unicrons/aws-root-manager/rootmanager"
+)
+
+func TestHistoryCommand_Success(t *testing.T) {
+	setOutputFlag(t, "csv")
+	dir := t.TempDir()
+	ids := saveSnapshots(t, dir,
+		[]rootmanager.RootCredentials{{AccountId: "123456789012", AccessKeys: []rootmanager.AccessKey{{AccessKeyId: "AKIA123", Status: "Active"}}}},
+		[]rootmanager.RootCredentials{{AccountId: "210987654321"}},
+		[]rootmanager.RootCredentials{{AccountId: "123456789012", Error: "access denied"}},
+	)
+
+	var buf bytes.Buffer
+	cmd := History()
+	cmd.SetOut(&buf)
+	cmd.SetArgs([]string{"123456789012", "--snapshot-dir", dir})
+
+	require.NoError(t, cmd.Execute())
+	out := buf.String()
+	assert.Contains(t, out, ids[0]+",")
+	assert.Contains(t, out, "AKIA123 (Active")
+	assert.NotContains(t, out, ids[1])
+	assert.Contains(t, out, ids[2]+",audit failed: access denied")
+}
+
+func TestHistoryCommand_AccountNotFound(t *testing.T) {
+	dir := t.TempDir()
+	saveSnapshots(t, dir, []rootmanager.RootCredentials{{AccountId: "210987654321"}})
+
+	cmd := History()
+	cmd.SilenceErrors = true
+	cmd.SetArgs([]string{"123456789012", "--snapshot-dir", dir})
+
+	err := cmd.Execute()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not found in any snapshot")
+}
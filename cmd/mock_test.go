@@ -2,23 +2,49 @@ package cmd
 
 import (
 	"context"
+	"os"
+	"testing"
+	"time"
 
+	"github.com/unicrons/aws-root-manager/internal/aws"
 	"github.com/unicrons/aws-root-manager/rootmanager"
 )
 
+// TestMain replaces the Organizations client so that no test calls AWS.
+func TestMain(m *testing.M) {
+	newOrganizationsClient = func(_ context.Context) (aws.OrganizationsClient, error) {
+		return &mockOrganizationsClient{}, nil
+	}
+	os.Exit(m.Run())
+}
+
 // mockRootManager implements rootmanager.RootManager for testing.
 type mockRootManager struct {
-	checkResult    rootmanager.RootAccessStatus
-	checkErr       error
-	auditResult    []rootmanager.RootCredentials
-	auditErr       error
-	enableInit     rootmanager.RootAccessStatus
-	enableFinal    rootmanager.RootAccessStatus
-	enableErr      error
-	deleteResult   []rootmanager.DeletionResult
-	deleteErr      error
-	recoveryResult []rootmanager.RecoveryResult
-	recoveryErr    error
+	checkResult       rootmanager.RootAccessStatus
+	checkErr          error
+	auditResult       []rootmanager.RootCredentials
+	auditErr          error
+	auditedAccounts   []string
+	enableInit        rootmanager.RootAccessStatus
+	enableFinal       rootmanager.RootAccessStatus
+	enableErr         error
+	deleteResult      []rootmanager.DeletionResult
+	deleteErr         error
+	deleteCalls       int
+	deletedCreds      []rootmanager.RootCredentials
+	deletedType       string
+	deleteRequests    []rootmanager.DeletionRequest
+	recoveryResult    []rootmanager.RecoveryResult
+	recoveryErr       error
+	recoveryCalls     int
+	recoveredAccounts []string
+
+	// audits made after a deletion or recovery return verifyResults in order (the last
+	// entry repeats), or by default the audited accounts without credentials (or with
+	// a login profile after a recovery)
+	verifyResults    [][]rootmanager.RootCredentials
+	verifyCalls      int
+	verifiedAccounts []string
 
 	getBucketPolicyResult string
 	getBucketPolicyErr    error
@@ -38,16 +64,39 @@ type mockRootManager struct {
 func (m *mockRootManager) CheckRootAccess(_ context.Context) (rootmanager.RootAccessStatus, error) {
 	return m.checkResult, m.checkErr
 }
-func (m *mockRootManager) AuditAccounts(_ context.Context, _ []string) ([]rootmanager.RootCredentials, error) {
-	return m.auditResult, m.auditErr
+func (m *mockRootManager) AuditAccounts(_ context.Context, accountIds []string) ([]rootmanager.RootCredentials, error) {
+	if m.deleteCalls == 0 && m.recoveryCalls == 0 {
+		m.auditedAccounts = accountIds
+		return m.auditResult, m.auditErr
+	}
+	m.verifiedAccounts = accountIds
+	m.verifyCalls++
+	if len(m.verifyResults) > 0 {
+		return m.verifyResults[min(m.verifyCalls, len(m.verifyResults))-1], nil
+	}
+	creds := make([]rootmanager.RootCredentials, len(accountIds))
+	for i, id := range accountIds {
+		creds[i] = rootmanager.RootCredentials{AccountId: id, LoginProfile: m.recoveryCalls > 0}
+	}
+	return creds, nil
 }
 func (m *mockRootManager) EnableRootAccess(_ context.Context, _ bool) (rootmanager.RootAccessStatus, rootmanager.RootAccessStatus, error) {
 	return m.enableInit, m.enableFinal, m.enableErr
 }
-func (m *mockRootManager) DeleteCredentials(_ context.Context, _ []rootmanager.RootCredentials, _ string) ([]rootmanager.DeletionResult, error) {
+func (m *mockRootManager) DeleteCredentials(_ context.Context, creds []rootmanager.RootCredentials, credentialType string) ([]rootmanager.DeletionResult, error) {
+	m.deleteCalls++
+	m.deletedCreds = creds
+	m.deletedType = credentialType
 	return m.deleteResult, m.deleteErr
 }
-func (m *mockRootManager) RecoverRootPassword(_ context.Context, _ []string) ([]rootmanager.RecoveryResult, error) {
+func (m *mockRootManager) DeleteCredentialItems(_ context.Context, requests []rootmanager.DeletionRequest) ([]rootmanager.DeletionResult, error) {
+	m.deleteCalls++
+	m.deleteRequests = requests
+	return m.deleteResult, m.deleteErr
+}
+func (m *mockRootManager) RecoverRootPassword(_ context.Context, accountIds []string) ([]rootmanager.RecoveryResult, error) {
+	m.recoveryCalls++
+	m.recoveredAccounts = accountIds
 	return m.recoveryResult, m.recoveryErr
 }
 func (m *mockRootManager) GetS3BucketPolicy(_ context.Context, _, _ string) (string, error) {
@@ -82,3 +131,70 @@ func newFailingFactory(err error) func(context.Context) (rootmanager.RootManager
 		return nil, err
 	}
 }
+
+// setOutputFlag sets the global output format for the duration of the test.
+func setOutputFlag(t *testing.T, format string) {
+	t.Helper()
+	previous := outputFlag
+	outputFlag = format
+	t.Cleanup(func() { outputFlag = previous })
+}
+
+// setVerificationBackoff replaces the delays between verification audits for the test.
+func setVerificationBackoff(t *testing.T, delays ...time.Duration) {
+	t.Helper()
+	previous := verificationBackoff
+	verificationBackoff = delays
+	t.Cleanup(func() { verificationBackoff = previous })
+}
+
+// mockOrganizationsClient implements aws.OrganizationsClient for testing.
+type mockOrganizationsClient struct {
+	managementAccount string
+	accounts          []aws.OrganizationAccount
+	listCalls         int
+}
+
+func (m *mockOrganizationsClient) DescribeOrganization(_ context.Context) (string, error) {
+	return m.managementAccount, nil
+}
+func (m *mockOrganizationsClient) ListAccounts(_ context.Context) ([]aws.OrganizationAccount, error) {
+	m.listCalls++
+	return m.accounts, nil
+}
+func (m *mockOrganizationsClient) ListAccountsForParent(_ context.Context, _ string) ([]aws.OrganizationAccount, error) {
+	return nil, nil
+}
+func (m *mockOrganizationsClient) ListChildOrganizationalUnits(_ context.Context, _ string) ([]string, error) {
+	return nil, nil
+}
+func (m *mockOrganizationsClient) ListTagsForResource(_ context.Context, _ string) (map[string]string, error) {
+	return nil, nil
+}
+func (m *mockOrganizationsClient) EnableAWSServiceAccess(_ context.Context, _ string) error {
+	return nil
+}
+
+// newStatusesMock returns an organization with an active, a suspended and a
+// pending closure member account.
+func newStatusesMock() *mockOrganizationsClient {
+	return &mockOrganizationsClient{
+		managementAccount: "999999999999",
+		accounts: []aws.OrganizationAccount{
+			{AccountID: "999999999999", Name: "management", Status: aws.AccountStatusActive},
+			{AccountID: "123456789012", Name: "active", Status: aws.AccountStatusActive},
+			{AccountID: "234567890123", Name: "suspended", Status: aws.AccountStatusSuspended},
+			{AccountID: "345678901234", Name: "closing", Status: aws.AccountStatusPendingClosure},
+		},
+	}
+}
+
+// setOrganizationsClient makes the commands use org for the duration of the test.
+func setOrganizationsClient(t *testing.T, org aws.OrganizationsClient) {
+	t.Helper()
+	previous := newOrganizationsClient
+	newOrganizationsClient = func(_ context.Context) (aws.OrganizationsClient, error) {
+		return org, nil
+	}
+	t.Cleanup(func() { newOrganizationsClient = previous })
+}
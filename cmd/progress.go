@@ -0,0 +1,24 @@
+package cmd
+
+import (
+	"context"
+
+	"github.com/unicrons/aws-root-manager/internal/cli/ui"
+	"github.com/unicrons/aws-root-manager/rootmanager"
+)
+
+var progressTitles = map[string]string{
+	rootmanager.OperationAudit:    "Auditing accounts",
+	rootmanager.OperationDelete:   "Deleting credentials",
+	rootmanager.OperationRecovery: "Recovering root passwords",
+}
+
+// withProgress returns a copy of ctx that displays the live progress of the
+// root manager operations run with it. The returned function stops the display.
+func withProgress(ctx context.Context) (context.Context, func()) {
+	progress := ui.StartProgress()
+	ctx = rootmanager.WithProgress(ctx, func(e rootmanager.ProgressEvent) {
+		progress.Update(progressTitles[e.Operation], e.Done, e.Failures, e.InFlight, e.Total)
+	})
+	return ctx, progress.Stop
+}
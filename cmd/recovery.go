@@ -2,11 +2,11 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"strings"
 
-	"github.com/unicrons/aws-root-manager/internal/aws"
 	"github.com/unicrons/aws-root-manager/internal/cli/output"
 	"github.com/unicrons/aws-root-manager/internal/cli/ui"
 	"github.com/unicrons/aws-root-manager/rootmanager"
@@ -22,28 +22,40 @@ func Recovery(newRM func(context.Context) (rootmanager.RootManager, error)) *cob
 		RunE: func(cmd *cobra.Command, args []string) error {
 			slog.Debug("recovery called")
 
-			ctx := context.Background()
+			ctx, cancel := commandContext()
+			defer cancel()
+
 			rm, err := newRM(ctx)
 			if err != nil {
 				slog.Error("failed to initialize root manager", "error", err)
 				return err
 			}
 
-			awscfg, err := aws.LoadAWSConfig(ctx)
+			selection, err := accountSelection(accountsFlags)
+			if err != nil {
+				return err
+			}
+
+			org, orgAccounts, err := organizationAccounts(ctx)
 			if err != nil {
-				return fmt.Errorf("failed to load aws config: %w", err)
+				return err
 			}
-			targetAccounts, err := ui.SelectTargetAccounts(ctx, aws.NewOrganizationsClient(awscfg), accountsFlags)
+			targetAccounts, err := ui.SelectTargetAccounts(ctx, org, orgAccounts, selection)
 			if err != nil {
 				slog.Error("failed to get target accounts", "error", err)
 				return err
 			}
+			targetAccounts = activeAccounts(orgAccounts, targetAccounts, cmd.ErrOrStderr())
 			if len(targetAccounts) == 0 {
 				slog.Info("no accounts selected")
 				return nil
 			}
 			slog.Debug("selected accounts", "accounts", strings.Join(targetAccounts, ", "))
 
+			if dryRunFlag {
+				return runRecoveryDryRun(ctx, rm, cmd, targetAccounts)
+			}
+
 			if !skipFlag {
 				confirmed, err := ui.Confirm(fmt.Sprintf("Restore root password for %d account(s)?", len(targetAccounts)))
 				if err != nil {
@@ -55,19 +67,26 @@ func Recovery(newRM func(context.Context) (rootmanager.RootManager, error)) *cob
 				}
 			}
 
-			results, err := rm.RecoverRootPassword(ctx, targetAccounts)
+			progressCtx, stopProgress := withProgress(ctx)
+			results, err := rm.RecoverRootPassword(progressCtx, targetAccounts)
+			stopProgress()
 			if err != nil {
 				slog.Error("failed to recover root password", "error", err)
 				return err
 			}
+			results = verifyRecovery(ctx, rm, results)
 
-			headers := []string{"Account", "Login Profile", "Error"}
+			headers := []string{"Account", "Login Profile", "Verified", "Error"}
 			var data [][]any
-			var failureCount int
+			var failureCount, cancelledCount, unverifiedCount, unknownCount int
 			for _, result := range results {
 				status := "recovered"
-				errorMsg := ""
-				if !result.Success {
+				errorMsg := result.VerificationError
+				if result.Cancelled {
+					status = "cancelled"
+					errorMsg = result.Error
+					cancelledCount++
+				} else if !result.Success {
 					if result.Error != "" {
 						status = "failed"
 						errorMsg = result.Error
@@ -76,19 +95,73 @@ func Recovery(newRM func(context.Context) (rootmanager.RootManager, error)) *cob
 						status = "already exists"
 					}
 				}
-				data = append(data, []any{result.AccountId, status, errorMsg})
+				switch result.Verification {
+				case rootmanager.VerificationFailed:
+					unverifiedCount++
+				case rootmanager.VerificationUnknown:
+					unknownCount++
+				}
+				data = append(data, []any{result.AccountId, status, result.Verification, errorMsg})
 			}
 
 			output.HandleOutput(cmd.OutOrStdout(), outputFlag, headers, data)
 
+			var errs []error
 			if failureCount > 0 {
-				return fmt.Errorf("recovery failed for %d account(s)", failureCount)
+				errs = append(errs, fmt.Errorf("recovery failed for %d account(s)", failureCount))
 			}
-
-			return nil
+			if cancelledCount > 0 {
+				errs = append(errs, fmt.Errorf("recovery cancelled for %d account(s)", cancelledCount))
+			}
+			if unverifiedCount > 0 {
+				errs = append(errs, fmt.Errorf("verification failed: login profile missing after recovery for %d account(s)", unverifiedCount))
+			}
+			if unknownCount > 0 {
+				errs = append(errs, fmt.Errorf("verification incomplete: %d recovered account(s) could not be audited again", unknownCount))
+			}
+			return errors.Join(errs...)
 		},
 	}
 	cmd.PersistentFlags().StringSliceVarP(&accountsFlags, "accounts", "a", []string{}, "List of tarjet AWS account IDs (comma-separated). Use \"all\" to select all accounts.")
+	addTargetFlags(cmd)
 	cmd.Flags().BoolVar(&skipFlag, "yes", false, "Skip the confirmation prompt")
+	cmd.Flags().BoolVar(&dryRunFlag, "dry-run", false, "Show which accounts would get root password recovery without making any changes")
 	return cmd
 }
+
+// runRecoveryDryRun audits the accounts and lists the ones whose root password
+// would be recovered, without making any changes.
+func runRecoveryDryRun(ctx context.Context, rm rootmanager.RootManager, cmd *cobra.Command, accountIds []string) error {
+	progressCtx, stopProgress := withProgress(ctx)
+	audit, err := rm.AuditAccounts(progressCtx, accountIds)
+	stopProgress()
+	if err != nil {
+		return err
+	}
+
+	headers := []string{"Account", "Login Profile", "Error"}
+	var data [][]any
+	var unknownCount int
+	for _, acc := range audit {
+		errorMsg := acc.Error
+		if errorMsg == "" {
+			errorMsg = acc.CheckError(rootmanager.CheckLogin)
+		}
+		switch {
+		case errorMsg != "":
+			unknownCount++
+			data = append(data, []any{acc.AccountId, "unknown", errorMsg})
+		case acc.LoginProfile:
+			data = append(data, []any{acc.AccountId, "already exists", ""})
+		default:
+			data = append(data, []any{acc.AccountId, "would recover", ""})
+		}
+	}
+	output.HandleOutput(cmd.OutOrStdout(), outputFlag, headers, data)
+	fmt.Fprintln(cmd.ErrOrStderr(), "Dry run: no changes were made.")
+
+	if unknownCount > 0 {
+		return fmt.Errorf("login profile unknown for %d account(s)", unknownCount)
+	}
+	return nil
+}
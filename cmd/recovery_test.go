@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -69,3 +70,99 @@ func TestRecoveryCommand_RecoveryFailure(t *testing.T) {
 
 	require.Error(t, cmd.Execute())
 }
+
+func TestRecoveryCommand_DryRun(t *testing.T) {
+	setOutputFlag(t, "csv")
+	mock := &mockRootManager{
+		auditResult: []rootmanager.RootCredentials{
+			{AccountId: "123456789012"},
+			{AccountId: "234567890123", LoginProfile: true},
+			{AccountId: "345678901234", Checks: []rootmanager.CheckResult{{Check: rootmanager.CheckLogin, Error: "throttled"}}},
+		},
+	}
+
+	var buf bytes.Buffer
+	cmd := Recovery(newMockFactory(mock))
+	cmd.SetOut(&buf)
+	cmd.SetArgs([]string{"--accounts", "123456789012,234567890123,345678901234", "--dry-run"})
+
+	err := cmd.Execute()
+	require.EqualError(t, err, "login profile unknown for 1 account(s)")
+	assert.Zero(t, mock.recoveryCalls)
+	out := buf.String()
+	assert.Contains(t, out, "123456789012,would recover,")
+	assert.Contains(t, out, "234567890123,already exists,")
+	assert.Contains(t, out, "345678901234,unknown,throttled")
+}
+
+func TestRecoveryCommand_Verification(t *testing.T) {
+	setOutputFlag(t, "csv")
+	mock := &mockRootManager{
+		recoveryResult: []rootmanager.RecoveryResult{
+			{AccountId: "123456789012", Success: true},
+			{AccountId: "234567890123", Success: true},
+			{AccountId: "345678901234"},
+		},
+		verifyResults: [][]rootmanager.RootCredentials{{
+			{AccountId: "123456789012", LoginProfile: true},
+			{AccountId: "234567890123"},
+		}},
+	}
+	setVerificationBackoff(t, 0)
+
+	var buf bytes.Buffer
+	cmd := Recovery(newMockFactory(mock))
+	cmd.SilenceErrors = true
+	cmd.SetOut(&buf)
+	cmd.SetArgs([]string{"--accounts", "123456789012,234567890123,345678901234", "--yes"})
+
+	err := cmd.Execute()
+	require.EqualError(t, err, "verification failed: login profile missing after recovery for 1 account(s)")
+	assert.Equal(t, 2, mock.verifyCalls)
+	assert.Equal(t, []string{"234567890123"}, mock.verifiedAccounts)
+	out := buf.String()
+	assert.Contains(t, out, "123456789012,recovered,verified,")
+	assert.Contains(t, out, "234567890123,recovered,failed,login profile missing after recovery")
+	assert.Contains(t, out, "345678901234,already exists,,")
+}
+
+func TestRecoveryCommand_VerificationRetry(t *testing.T) {
+	setOutputFlag(t, "csv")
+	mock := &mockRootManager{
+		recoveryResult: []rootmanager.RecoveryResult{{AccountId: "123456789012", Success: true}},
+		verifyResults: [][]rootmanager.RootCredentials{
+			// the new login profile is not visible yet
+			{{AccountId: "123456789012"}},
+			{{AccountId: "123456789012", LoginProfile: true}},
+		},
+	}
+	setVerificationBackoff(t, time.Millisecond)
+
+	var buf bytes.Buffer
+	cmd := Recovery(newMockFactory(mock))
+	cmd.SetOut(&buf)
+	cmd.SetArgs([]string{"--accounts", "123456789012", "--yes"})
+
+	require.NoError(t, cmd.Execute())
+	assert.Equal(t, 2, mock.verifyCalls)
+	assert.Contains(t, buf.String(), "123456789012,recovered,verified,")
+}
+
+func TestRecoveryCommand_SkipsInactiveAccounts(t *testing.T) {
+	setOrganizationsClient(t, newStatusesMock())
+	mock := &mockRootManager{
+		recoveryResult: []rootmanager.RecoveryResult{
+			{AccountId: "123456789012", Success: true},
+		},
+	}
+
+	var errOut bytes.Buffer
+	cmd := Recovery(newMockFactory(mock))
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetErr(&errOut)
+	cmd.SetArgs([]string{"--accounts", "123456789012,234567890123,345678901234", "--yes"})
+
+	require.NoError(t, cmd.Execute())
+	assert.Equal(t, []string{"123456789012"}, mock.recoveredAccounts)
+	assert.Contains(t, errOut.String(), "Skipping 2 account(s) that are not active and cannot be changed: 234567890123 (SUSPENDED), 345678901234 (PENDING_CLOSURE)")
+}
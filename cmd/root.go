@@ -1,8 +1,12 @@
 package cmd
 
 import (
+	"context"
+	"fmt"
 	"os"
+	"time"
 
+	"github.com/unicrons/aws-root-manager/internal/aws"
 	"github.com/unicrons/aws-root-manager/internal/logger"
 	"github.com/unicrons/aws-root-manager/rootmanager"
 
@@ -10,9 +14,31 @@ import (
 )
 
 var (
-	accountsFlags []string
-	outputFlag    string
-	skipFlag      bool
+	accountsFlags          []string
+	outputFlag             string
+	skipFlag               bool
+	dryRunFlag             bool
+	planOutFlag            string
+	allowStaleFlag         bool
+	itemIdsFlag            []string
+	pickItemsFlag          bool
+	typesFlag              []string
+	concurrencyFlag        int
+	assumeRootRateFlag     float64
+	timeoutFlag            time.Duration
+	assumeRootDurationFlag time.Duration
+	snapshotDirFlag        string
+	exceptionsFlag         string
+	failOnFindingsFlag     bool
+	breakGlassFlag         string
+	ouFlags                []string
+	recursiveFlag          bool
+	tagFlags               []string
+	tagMatchFlag           string
+	excludeFlags           []string
+	nameMatchFlag          string
+	accountsFileFlag       string
+	includeStatusFlags     []string
 )
 
 var rootCmd = &cobra.Command{
@@ -43,10 +69,34 @@ func init() {
 	logger.Configure(os.Getenv("LOG_LEVEL"), os.Getenv("LOG_FORMAT"))
 
 	rootCmd.PersistentFlags().StringVarP(&outputFlag, "output", "o", "table", "Set the output format (table, json, csv)")
-	rootCmd.AddCommand(Audit(rootmanager.NewRootManager))
-	rootCmd.AddCommand(Check(rootmanager.NewRootManager))
-	rootCmd.AddCommand(Enable(rootmanager.NewRootManager))
-	rootCmd.AddCommand(Delete(rootmanager.NewRootManager))
-	rootCmd.AddCommand(Recovery(rootmanager.NewRootManager))
+	rootCmd.PersistentFlags().IntVar(&concurrencyFlag, "concurrency", rootmanager.DefaultConcurrency, "Maximum number of accounts processed in parallel")
+	rootCmd.PersistentFlags().Float64Var(&assumeRootRateFlag, "assume-root-rate", rootmanager.DefaultAssumeRootRate, "Maximum sts:AssumeRoot calls per second, lowered automatically on throttling (0 disables the limit)")
+	rootCmd.PersistentFlags().DurationVar(&assumeRootDurationFlag, "assume-root-duration", rootmanager.DefaultAssumeRootDuration, "Duration of sts:AssumeRoot sessions, renewed automatically before expiry (1m to 15m)")
+	rootCmd.PersistentFlags().DurationVar(&timeoutFlag, "timeout", 0, "Stop starting new accounts after this duration and report partial results (e.g. 30m, 0 disables the timeout)")
+	rootCmd.AddCommand(Audit(newRootManager))
+	rootCmd.AddCommand(Check(newRootManager))
+	rootCmd.AddCommand(Enable(newRootManager))
+	rootCmd.AddCommand(Delete(newRootManager))
+	rootCmd.AddCommand(Recovery(newRootManager))
+	rootCmd.AddCommand(History())
 	rootCmd.AddCommand(Version())
 }
+
+// newRootManager creates the RootManager used by the commands, configured from the global flags.
+func newRootManager(ctx context.Context) (rootmanager.RootManager, error) {
+	return rootmanager.NewRootManager(ctx,
+		rootmanager.WithConcurrency(concurrencyFlag),
+		rootmanager.WithAssumeRootRate(assumeRootRateFlag),
+		rootmanager.WithAssumeRootDuration(assumeRootDurationFlag),
+	)
+}
+
+// newOrganizationsClient creates the Organizations client used by the commands to
+// select the target accounts. Tests replace it to avoid calling AWS.
+var newOrganizationsClient = func(ctx context.Context) (aws.OrganizationsClient, error) {
+	awscfg, err := aws.LoadAWSConfig(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load aws config: %w", err)
+	}
+	return aws.NewOrganizationsClient(awscfg), nil
+}
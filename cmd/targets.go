@@ -0,0 +1,143 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/unicrons/aws-root-manager/internal/aws"
+	"github.com/unicrons/aws-root-manager/internal/cli/ui"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	tagMatchAll = "all"
+	tagMatchAny = "any"
+)
+
+// addTargetFlags adds the flags that select target accounts from a file, by
+// organization structure, tags and name to a multi-account command, alongside
+// its --accounts flag.
+func addTargetFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&accountsFileFlag, "accounts-file", "", "File with the AWS account IDs to target, one per line or as a json array. Use \"-\" to read from stdin. Combined with --accounts.")
+	cmd.Flags().StringSliceVar(&ouFlags, "ou", []string{}, "Organizational unit (ou-...) or root (r-...) whose accounts are targeted (repeatable, comma-separated). Combined with account IDs in --accounts, not with --accounts all.")
+	cmd.Flags().BoolVar(&recursiveFlag, "recursive", false, "Include accounts in organizational units nested under --ou")
+	cmd.Flags().StringArrayVar(&tagFlags, "tag", []string{}, "Only target accounts with this organization tag, as key=value (repeatable). Without --accounts, --accounts-file or --ou, all accounts are filtered.")
+	cmd.Flags().StringVar(&tagMatchFlag, "tag-match", tagMatchAll, "Whether accounts must match all --tag filters or any of them (all, any)")
+	cmd.Flags().StringSliceVar(&excludeFlags, "exclude", []string{}, "Skip accounts by account ID, organizational unit (including nested ones) or account name regular expression (repeatable, comma-separated)")
+	cmd.Flags().StringVar(&nameMatchFlag, "name-match", "", "Only target accounts whose name matches this regular expression")
+	cmd.Flags().StringSliceVar(&includeStatusFlags, "include-status", []string{}, "Also target organization accounts with these statuses besides active ones (suspended, pending_closure, pending_activation, closed)")
+}
+
+// accountSelection returns the target accounts requested through the flags.
+func accountSelection(accounts []string) (ui.AccountSelection, error) {
+	var fromFile []string
+	if accountsFileFlag != "" {
+		var err error
+		if fromFile, err = ui.ReadAccountsFile(accountsFileFlag, os.Stdin); err != nil {
+			return ui.AccountSelection{}, err
+		}
+	}
+	tags, err := parseTagFilters(tagFlags)
+	if err != nil {
+		return ui.AccountSelection{}, err
+	}
+	if tagMatchFlag != tagMatchAll && tagMatchFlag != tagMatchAny {
+		return ui.AccountSelection{}, fmt.Errorf("invalid --tag-match %q: expected %s or %s", tagMatchFlag, tagMatchAll, tagMatchAny)
+	}
+	statuses, err := parseAccountStatuses(includeStatusFlags)
+	if err != nil {
+		return ui.AccountSelection{}, err
+	}
+
+	return ui.AccountSelection{
+		Accounts:      accounts,
+		FromFile:      fromFile,
+		OUs:           ouFlags,
+		Recursive:     recursiveFlag,
+		Tags:          tags,
+		MatchAnyTag:   tagMatchFlag == tagMatchAny,
+		Exclude:       excludeFlags,
+		NameMatch:     nameMatchFlag,
+		IncludeStatus: statuses,
+		Concurrency:   concurrencyFlag,
+	}, nil
+}
+
+// parseAccountStatuses parses the account statuses to target besides active ones.
+func parseAccountStatuses(values []string) ([]string, error) {
+	valid := []string{aws.AccountStatusSuspended, aws.AccountStatusPendingClosure, aws.AccountStatusPendingActivation, aws.AccountStatusClosed}
+	statuses := make([]string, len(values))
+	for i, value := range values {
+		status := strings.ToUpper(strings.ReplaceAll(value, "-", "_"))
+		if !slices.Contains(valid, status) {
+			return nil, fmt.Errorf("invalid --include-status %q: expected one of %s", value, strings.ToLower(strings.Join(valid, ", ")))
+		}
+		statuses[i] = status
+	}
+	return statuses, nil
+}
+
+// organizationAccounts creates the Organizations client and lists the member
+// accounts of the organization once, for the account selection and status checks
+// of a command.
+func organizationAccounts(ctx context.Context) (aws.OrganizationsClient, []aws.OrganizationAccount, error) {
+	org, err := newOrganizationsClient(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	orgAccounts, err := ui.ListOrganizationAccounts(ctx, org)
+	if err != nil {
+		return nil, nil, err
+	}
+	return org, orgAccounts, nil
+}
+
+// inactiveAccounts returns the status of the accounts that are not active, which
+// cannot be accessed with AssumeRoot.
+func inactiveAccounts(orgAccounts []aws.OrganizationAccount, accounts []string) map[string]string {
+	inactive := make(map[string]string)
+	for _, account := range orgAccounts {
+		if account.Status != aws.AccountStatusActive && slices.Contains(accounts, account.AccountID) {
+			inactive[account.AccountID] = account.Status
+		}
+	}
+	return inactive
+}
+
+// activeAccounts removes the accounts that are not active from accounts, since
+// AssumeRoot cannot access them to make changes, and reports the removed ones to w.
+func activeAccounts(orgAccounts []aws.OrganizationAccount, accounts []string, w io.Writer) []string {
+	inactive := inactiveAccounts(orgAccounts, accounts)
+	if len(inactive) == 0 {
+		return accounts
+	}
+
+	var skipped []string
+	for _, accountId := range slices.Sorted(maps.Keys(inactive)) {
+		skipped = append(skipped, fmt.Sprintf("%s (%s)", accountId, inactive[accountId]))
+	}
+	fmt.Fprintf(w, "Skipping %d account(s) that are not active and cannot be changed: %s\n", len(skipped), strings.Join(skipped, ", "))
+	return slices.DeleteFunc(slices.Clone(accounts), func(accountId string) bool {
+		_, ok := inactive[accountId]
+		return ok
+	})
+}
+
+// parseTagFilters parses key=value tag filters.
+func parseTagFilters(values []string) ([]aws.TagFilter, error) {
+	filters := make([]aws.TagFilter, len(values))
+	for i, value := range values {
+		key, tagValue, ok := strings.Cut(value, "=")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid --tag %q: expected key=value", value)
+		}
+		filters[i] = aws.TagFilter{Key: key, Value: tagValue}
+	}
+	return filters, nil
+}
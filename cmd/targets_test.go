@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/unicrons/aws-root-manager/internal/aws"
+)
+
+func TestParseTagFilters(t *testing.T) {
+	tests := []struct {
+		name    string
+		values  []string
+		want    []aws.TagFilter
+		wantErr string
+	}{
+		{
+			name:   "key and value",
+			values: []string{"env=sandbox", "team=platform"},
+			want:   []aws.TagFilter{{Key: "env", Value: "sandbox"}, {Key: "team", Value: "platform"}},
+		},
+		{
+			name:   "value containing equals sign",
+			values: []string{"expr=a=b"},
+			want:   []aws.TagFilter{{Key: "expr", Value: "a=b"}},
+		},
+		{
+			name:   "empty value",
+			values: []string{"env="},
+			want:   []aws.TagFilter{{Key: "env", Value: ""}},
+		},
+		{
+			name:    "missing value",
+			values:  []string{"env"},
+			wantErr: `invalid --tag "env": expected key=value`,
+		},
+		{
+			name:    "missing key",
+			values:  []string{"=sandbox"},
+			wantErr: `invalid --tag "=sandbox": expected key=value`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseTagFilters(tt.values)
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestDeleteCommand_InvalidTagMatch(t *testing.T) {
+	setOutputFlag(t, "table")
+	cmd := Delete(newMockFactory(&mockRootManager{}))
+	cmd.SetArgs([]string{"all", "--accounts", "123456789012", "--tag", "env=sandbox", "--tag-match", "some"})
+	err := cmd.Execute()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `invalid --tag-match "some"`)
+}
+
+func TestDeleteCommand_InvalidAccountsFile(t *testing.T) {
+	setOutputFlag(t, "table")
+	path := filepath.Join(t.TempDir(), "accounts.txt")
+	require.NoError(t, os.WriteFile(path, []byte("123456789012\nnot-an-account\n"), 0o600))
+
+	mock := &mockRootManager{}
+	cmd := Delete(newMockFactory(mock))
+	cmd.SetArgs([]string{"all", "--accounts-file", path, "--yes"})
+	err := cmd.Execute()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `line 2: invalid account ID "not-an-account"`)
+}
+
+func TestParseAccountStatuses(t *testing.T) {
+	statuses, err := parseAccountStatuses([]string{"suspended", "PENDING_CLOSURE", "pending-activation"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{aws.AccountStatusSuspended, aws.AccountStatusPendingClosure, aws.AccountStatusPendingActivation}, statuses)
+
+	_, err = parseAccountStatuses([]string{"active"})
+	assert.EqualError(t, err, `invalid --include-status "active": expected one of suspended, pending_closure, pending_activation, closed`)
+}
@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/unicrons/aws-root-manager/rootmanager"
+)
+
+// verificationBackoff is the delay before each new verification audit of the
+// accounts whose changes are not visible yet. IAM is eventually consistent, so
+// a credential that was just deleted can still be listed for a few seconds.
+var verificationBackoff = []time.Duration{2 * time.Second, 4 * time.Second, 8 * time.Second}
+
+// verifyDeletion audits again the accounts in which credentials were deleted
+// and checks that these credentials are gone.
+func verifyDeletion(ctx context.Context, rm rootmanager.RootManager, results []rootmanager.DeletionResult) []rootmanager.DeletionResult {
+	var accountIds []string
+	for _, result := range results {
+		if slices.ContainsFunc(result.Items, func(item rootmanager.CredentialItemResult) bool { return item.Success }) {
+			accountIds = append(accountIds, result.AccountId)
+		}
+	}
+	if len(accountIds) == 0 {
+		return results
+	}
+
+	audit := auditUntilVerified(ctx, rm, accountIds, func(audit []rootmanager.RootCredentials) []string {
+		var unverified []string
+		for _, result := range rootmanager.VerifyDeletion(results, audit) {
+			if slices.ContainsFunc(result.Items, func(item rootmanager.CredentialItemResult) bool {
+				return item.Verification == rootmanager.VerificationFailed
+			}) {
+				unverified = append(unverified, result.AccountId)
+			}
+		}
+		return unverified
+	})
+	return rootmanager.VerifyDeletion(results, audit)
+}
+
+// verifyRecovery audits again the accounts whose root password was recovered
+// and checks that they now have a login profile.
+func verifyRecovery(ctx context.Context, rm rootmanager.RootManager, results []rootmanager.RecoveryResult) []rootmanager.RecoveryResult {
+	var accountIds []string
+	for _, result := range results {
+		if result.Success {
+			accountIds = append(accountIds, result.AccountId)
+		}
+	}
+	if len(accountIds) == 0 {
+		return results
+	}
+
+	audit := auditUntilVerified(ctx, rm, accountIds, func(audit []rootmanager.RootCredentials) []string {
+		var unverified []string
+		for _, result := range rootmanager.VerifyRecovery(results, audit) {
+			if result.Verification == rootmanager.VerificationFailed {
+				unverified = append(unverified, result.AccountId)
+			}
+		}
+		return unverified
+	})
+	return rootmanager.VerifyRecovery(results, audit)
+}
+
+// auditUntilVerified audits the accounts, then audits again, after each delay
+// of verificationBackoff, the accounts that unverified reports as not showing
+// the change yet. Returns the latest audit of every account; accounts whose
+// audit failed altogether are missing from it.
+func auditUntilVerified(ctx context.Context, rm rootmanager.RootManager, accountIds []string, unverified func([]rootmanager.RootCredentials) []string) []rootmanager.RootCredentials {
+	var audit []rootmanager.RootCredentials
+	for attempt := 0; ; attempt++ {
+		progressCtx, stopProgress := withProgress(ctx)
+		creds, err := rm.AuditAccounts(progressCtx, accountIds)
+		stopProgress()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Verification audit failed: %v\n", err)
+			return audit
+		}
+		for _, accountCreds := range creds {
+			idx := slices.IndexFunc(audit, func(c rootmanager.RootCredentials) bool { return c.AccountId == accountCreds.AccountId })
+			if idx < 0 {
+				audit = append(audit, accountCreds)
+			} else {
+				audit[idx] = accountCreds
+			}
+		}
+
+		accountIds = unverified(audit)
+		if len(accountIds) == 0 || attempt == len(verificationBackoff) {
+			return audit
+		}
+		select {
+		case <-time.After(verificationBackoff[attempt]):
+		case <-ctx.Done():
+			return audit
+		}
+	}
+}
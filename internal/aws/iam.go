@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"log/slog"
 	"slices"
+	"strings"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/iam"
@@ -16,6 +18,46 @@ type iamClient struct {
 	client *iam.Client
 }
 
+// LoginProfile describes the root user login profile (password).
+type LoginProfile struct {
+	Exists     bool
+	CreateDate time.Time
+}
+
+// AccessKey describes a root access key and when it was last used.
+// LastUsedDate is zero when the key has never been used. LastUsedError is set
+// when the last usage could not be retrieved.
+type AccessKey struct {
+	AccessKeyId     string
+	Status          string
+	CreateDate      time.Time
+	LastUsedDate    time.Time
+	LastUsedService string
+	LastUsedRegion  string
+	LastUsedError   string
+}
+
+// SigningCertificate describes a root signing certificate.
+type SigningCertificate struct {
+	CertificateId string
+	Status        string
+	UploadDate    time.Time
+}
+
+const (
+	MFADeviceTypeVirtual  = "virtual"
+	MFADeviceTypeHardware = "hardware"
+	MFADeviceTypeFIDO     = "fido"
+	MFADeviceTypeUnknown  = "unknown"
+)
+
+// MFADevice describes a root MFA device.
+type MFADevice struct {
+	SerialNumber string
+	Type         string
+	EnableDate   time.Time
+}
+
 func NewIamClient(awscfg aws.Config) IamClient {
 	client := iam.NewFromConfig(awscfg)
 	return &iamClient{client: client}
@@ -52,20 +94,39 @@ func (c *iamClient) CheckOrganizationRootAccess(ctx context.Context, rootSession
 }
 
 // Check if an account has root login profile enabled
-func (c *iamClient) GetLoginProfile(ctx context.Context, accountId string) (bool, error) {
+func (c *iamClient) GetLoginProfile(ctx context.Context, accountId string) (LoginProfile, error) {
 	slog.Debug("getting login profile", "account_id", accountId)
 
-	_, err := c.client.GetLoginProfile(ctx, &iam.GetLoginProfileInput{})
+	output, err := c.client.GetLoginProfile(ctx, &iam.GetLoginProfileInput{})
 	if err != nil {
 		var notFoundErr *types.NoSuchEntityException
 		if errors.As(err, &notFoundErr) {
 			slog.Debug("account does not have a root login profile", "account_id", accountId)
-			return false, nil
+			return LoginProfile{}, nil
 		}
-		return true, fmt.Errorf("error getting root login profile for account %s: %w", accountId, err)
+		return LoginProfile{Exists: true}, fmt.Errorf("error getting root login profile for account %s: %w", accountId, err)
+	}
+
+	loginProfile := LoginProfile{Exists: true}
+	if output.LoginProfile != nil {
+		loginProfile.CreateDate = aws.ToTime(output.LoginProfile.CreateDate)
+	}
+	return loginProfile, nil
+}
+
+// Get the last time the root password was used to sign in for a specific account
+func (c *iamClient) GetPasswordLastUsed(ctx context.Context, accountId string) (time.Time, error) {
+	slog.Debug("getting root user", "account_id", accountId)
+
+	output, err := c.client.GetUser(ctx, &iam.GetUserInput{})
+	if err != nil {
+		return time.Time{}, fmt.Errorf("error getting root user for account %s: %w", accountId, err)
 	}
 
-	return true, nil
+	if output.User == nil {
+		return time.Time{}, nil
+	}
+	return aws.ToTime(output.User.PasswordLastUsed), nil
 }
 
 // Delete root login profile for a specific account
@@ -80,8 +141,8 @@ func (c *iamClient) DeleteLoginProfile(ctx context.Context, accountId string) er
 	return nil
 }
 
-// Get a list of root access keys for a specific account
-func (c *iamClient) ListAccessKeys(ctx context.Context, accountId string) ([]string, error) {
+// Get a list of root access keys, with their last usage, for a specific account
+func (c *iamClient) ListAccessKeys(ctx context.Context, accountId string) ([]AccessKey, error) {
 	slog.Debug("listing access keys", "account_id", accountId)
 
 	accessKeys, err := c.client.ListAccessKeys(ctx, &iam.ListAccessKeysInput{})
@@ -89,12 +150,29 @@ func (c *iamClient) ListAccessKeys(ctx context.Context, accountId string) ([]str
 		return nil, fmt.Errorf("error listing root access keys for account %s: %w", accountId, err)
 	}
 
-	// convert []AccessKeyMetadata to []string
-	var accessKeyIDs []string
+	var keys []AccessKey
 	for _, key := range accessKeys.AccessKeyMetadata {
-		accessKeyIDs = append(accessKeyIDs, *key.AccessKeyId)
+		accessKey := AccessKey{
+			AccessKeyId: aws.ToString(key.AccessKeyId),
+			Status:      string(key.Status),
+			CreateDate:  aws.ToTime(key.CreateDate),
+		}
+
+		lastUsed, err := c.client.GetAccessKeyLastUsed(ctx, &iam.GetAccessKeyLastUsedInput{
+			AccessKeyId: key.AccessKeyId,
+		})
+		// the key is still reported without its last usage, so that it can be deleted
+		if err != nil {
+			accessKey.LastUsedError = fmt.Sprintf("error getting last usage of access key %s for account %s: %v", accessKey.AccessKeyId, accountId, err)
+		} else if lastUsed.AccessKeyLastUsed != nil {
+			accessKey.LastUsedDate = aws.ToTime(lastUsed.AccessKeyLastUsed.LastUsedDate)
+			accessKey.LastUsedService = aws.ToString(lastUsed.AccessKeyLastUsed.ServiceName)
+			accessKey.LastUsedRegion = aws.ToString(lastUsed.AccessKeyLastUsed.Region)
+		}
+
+		keys = append(keys, accessKey)
 	}
-	return accessKeyIDs, nil
+	return keys, nil
 }
 
 // Delete a list of root access for a specific account
@@ -114,18 +192,41 @@ func (c *iamClient) DeleteAccessKeys(ctx context.Context, accountId string, acce
 }
 
 // Get a list of root MFA devices for a specific account
-func (c *iamClient) ListMFADevices(ctx context.Context, accountId string) ([]string, error) {
+func (c *iamClient) ListMFADevices(ctx context.Context, accountId string) ([]MFADevice, error) {
 	mfaDevices, err := c.client.ListMFADevices(ctx, &iam.ListMFADevicesInput{})
 	if err != nil {
 		return nil, fmt.Errorf("error listing root mfa devices for account %s: %w", accountId, err)
 	}
 
-	// convert []MFADevices to []string
-	var serialNumbers []string
+	var devices []MFADevice
 	for _, device := range mfaDevices.MFADevices {
-		serialNumbers = append(serialNumbers, *device.SerialNumber)
+		serialNumber := aws.ToString(device.SerialNumber)
+		devices = append(devices, MFADevice{
+			SerialNumber: serialNumber,
+			Type:         mfaDeviceType(serialNumber),
+			EnableDate:   aws.ToTime(device.EnableDate),
+		})
+	}
+	return devices, nil
+}
+
+// mfaDeviceType derives the MFA device type from its serial number.
+// Virtual devices use an ARN with the "mfa/" resource type and FIDO security keys
+// use "u2f/", while hardware TOTP tokens are identified by a plain serial number.
+func mfaDeviceType(serialNumber string) string {
+	arn, isArn := strings.CutPrefix(serialNumber, "arn:")
+	if !isArn {
+		return MFADeviceTypeHardware
+	}
+	resource := arn[strings.LastIndex(arn, ":")+1:]
+	switch {
+	case strings.HasPrefix(resource, "mfa/"):
+		return MFADeviceTypeVirtual
+	case strings.HasPrefix(resource, "u2f/"):
+		return MFADeviceTypeFIDO
+	default:
+		return MFADeviceTypeUnknown
 	}
-	return serialNumbers, nil
 }
 
 // Deactivate a list of root MFA devices for a specific account
@@ -144,19 +245,22 @@ func (c *iamClient) DeactivateMFADevices(ctx context.Context, accountId string,
 	return nil
 }
 
-// Get a list of root signing certificates devices for a specific account
-func (c *iamClient) ListSigningCertificates(ctx context.Context, accountId string) ([]string, error) {
+// Get a list of root signing certificates for a specific account
+func (c *iamClient) ListSigningCertificates(ctx context.Context, accountId string) ([]SigningCertificate, error) {
 	certificates, err := c.client.ListSigningCertificates(ctx, &iam.ListSigningCertificatesInput{})
 	if err != nil {
 		return nil, fmt.Errorf("error listing signing certificates for account %s: %w", accountId, err)
 	}
 
-	// convert []SigningCertificate to []string
-	var certificateIDs []string
+	var signingCertificates []SigningCertificate
 	for _, certificate := range certificates.Certificates {
-		certificateIDs = append(certificateIDs, *certificate.CertificateId)
+		signingCertificates = append(signingCertificates, SigningCertificate{
+			CertificateId: aws.ToString(certificate.CertificateId),
+			Status:        string(certificate.Status),
+			UploadDate:    aws.ToTime(certificate.UploadDate),
+		})
 	}
-	return certificateIDs, nil
+	return signingCertificates, nil
 }
 
 // Delete a list of root signing certificates for a specific account
@@ -167,7 +271,7 @@ func (c *iamClient) DeleteSigningCertificates(ctx context.Context, accountId str
 		if _, err := c.client.DeleteSigningCertificate(ctx, &iam.DeleteSigningCertificateInput{
 			CertificateId: aws.String(certificate),
 		}); err != nil {
-			return err
+			return fmt.Errorf("error deleting signing certificate %s for account %s: %w", certificate, accountId, err)
 		}
 	}
 
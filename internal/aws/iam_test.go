@@ -0,0 +1,71 @@
+package aws
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMFADeviceType(t *testing.T) {
+	tests := []struct {
+		serialNumber string
+		want         string
+	}{
+		{"arn:aws:iam::123456789012:mfa/root-account-mfa-device", MFADeviceTypeVirtual},
+		{"arn:aws:iam::123456789012:u2f/root-account-mfa-device/ABCDEF", MFADeviceTypeFIDO},
+		{"GAHT12345678", MFADeviceTypeHardware},
+		{"arn:aws:iam::123456789012:other/device", MFADeviceTypeUnknown},
+	}
+	for _, tt := range tests {
+		t.Run(tt.serialNumber, func(t *testing.T) {
+			assert.Equal(t, tt.want, mfaDeviceType(tt.serialNumber))
+		})
+	}
+}
+
+func TestIamClient_ListAccessKeysLastUsedError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		w.Header().Set("Content-Type", "text/xml")
+		switch r.PostForm.Get("Action") {
+		case "ListAccessKeys":
+			w.Write([]byte(`<ListAccessKeysResponse xmlns="https://iam.amazonaws.com/doc/2010-05-08/"><ListAccessKeysResult><AccessKeyMetadata>` +
+				`<member><AccessKeyId>AKIA1</AccessKeyId><Status>Active</Status></member>` +
+				`<member><AccessKeyId>AKIA2</AccessKeyId><Status>Inactive</Status></member>` +
+				`</AccessKeyMetadata><IsTruncated>false</IsTruncated></ListAccessKeysResult></ListAccessKeysResponse>`))
+		case "GetAccessKeyLastUsed":
+			if r.PostForm.Get("AccessKeyId") == "AKIA1" {
+				w.WriteHeader(http.StatusForbidden)
+				w.Write([]byte(`<ErrorResponse><Error><Type>Sender</Type><Code>AccessDenied</Code><Message>denied</Message></Error></ErrorResponse>`))
+				return
+			}
+			w.Write([]byte(`<GetAccessKeyLastUsedResponse xmlns="https://iam.amazonaws.com/doc/2010-05-08/"><GetAccessKeyLastUsedResult>` +
+				`<AccessKeyLastUsed><ServiceName>s3</ServiceName><Region>eu-west-1</Region></AccessKeyLastUsed>` +
+				`</GetAccessKeyLastUsedResult></GetAccessKeyLastUsedResponse>`))
+		}
+	}))
+	defer server.Close()
+
+	client := NewIamClient(aws.Config{
+		Region:       defaultRegion,
+		BaseEndpoint: aws.String(server.URL),
+		Credentials:  credentials.NewStaticCredentialsProvider("akid", "secret", ""),
+		Retryer:      func() aws.Retryer { return aws.NopRetryer{} },
+	})
+
+	keys, err := client.ListAccessKeys(context.Background(), "123456789012")
+	require.NoError(t, err)
+	require.Len(t, keys, 2)
+	assert.Equal(t, "AKIA1", keys[0].AccessKeyId)
+	assert.Contains(t, keys[0].LastUsedError, "error getting last usage of access key AKIA1")
+	assert.Empty(t, keys[0].LastUsedService)
+	assert.Equal(t, "AKIA2", keys[1].AccessKeyId)
+	assert.Empty(t, keys[1].LastUsedError)
+	assert.Equal(t, "s3", keys[1].LastUsedService)
+}
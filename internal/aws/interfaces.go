@@ -2,6 +2,7 @@ package aws
 
 import (
 	"context"
+	"time"
 
 	awssdk "github.com/aws/aws-sdk-go-v2/aws"
 )
@@ -13,25 +14,28 @@ type IamClient interface {
 	CheckOrganizationRootAccess(ctx context.Context, rootSessionsRequired bool) error
 
 	// GetLoginProfile checks if an account has root login profile enabled
-	GetLoginProfile(ctx context.Context, accountId string) (bool, error)
+	GetLoginProfile(ctx context.Context, accountId string) (LoginProfile, error)
+
+	// GetPasswordLastUsed gets the last time the root password was used to sign in (zero if never)
+	GetPasswordLastUsed(ctx context.Context, accountId string) (time.Time, error)
 
 	// DeleteLoginProfile deletes root login profile for a specific account
 	DeleteLoginProfile(ctx context.Context, accountId string) error
 
-	// ListAccessKeys gets a list of root access keys for a specific account
-	ListAccessKeys(ctx context.Context, accountId string) ([]string, error)
+	// ListAccessKeys gets a list of root access keys, with their last usage, for a specific account
+	ListAccessKeys(ctx context.Context, accountId string) ([]AccessKey, error)
 
 	// DeleteAccessKeys deletes a list of root access keys for a specific account
 	DeleteAccessKeys(ctx context.Context, accountId string, accessKeyIds []string) error
 
 	// ListMFADevices gets a list of root MFA devices for a specific account
-	ListMFADevices(ctx context.Context, accountId string) ([]string, error)
+	ListMFADevices(ctx context.Context, accountId string) ([]MFADevice, error)
 
 	// DeactivateMFADevices deactivates a list of root MFA devices for a specific account
 	DeactivateMFADevices(ctx context.Context, accountId string, mfaSerialNumbers []string) error
 
 	// ListSigningCertificates gets a list of root signing certificates for a specific account
-	ListSigningCertificates(ctx context.Context, accountId string) ([]string, error)
+	ListSigningCertificates(ctx context.Context, accountId string) ([]SigningCertificate, error)
 
 	// DeleteSigningCertificates deletes a list of root signing certificates for a specific account
 	DeleteSigningCertificates(ctx context.Context, accountId string, certificates []string) error
@@ -84,6 +88,15 @@ type OrganizationsClient interface {
 	// ListAccounts returns all accounts in the organization
 	ListAccounts(ctx context.Context) ([]OrganizationAccount, error)
 
+	// ListAccountsForParent returns the accounts directly under a root or organizational unit
+	ListAccountsForParent(ctx context.Context, parentId string) ([]OrganizationAccount, error)
+
+	// ListChildOrganizationalUnits returns the IDs of the organizational units directly under a root or organizational unit
+	ListChildOrganizationalUnits(ctx context.Context, parentId string) ([]string, error)
+
+	// ListTagsForResource returns the tags of an account, organizational unit or root by key
+	ListTagsForResource(ctx context.Context, resourceId string) (map[string]string, error)
+
 	// EnableAWSServiceAccess enables AWS service access for the organization
 	EnableAWSServiceAccess(ctx context.Context, service string) error
 }
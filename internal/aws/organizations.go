@@ -4,27 +4,80 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"regexp"
+	"slices"
+	"sync"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/organizations"
 	"github.com/aws/aws-sdk-go-v2/service/organizations/types"
 )
 
+// organizationsRequestsPerSecond is the initial rate of Organizations requests,
+// lowered automatically on throttling.
+const organizationsRequestsPerSecond = 10
+
+// DefaultTagLookupConcurrency is the number of accounts whose tags are looked up
+// in parallel when no concurrency is given to FilterAccountsByTags.
+const DefaultTagLookupConcurrency = 10
+
 type organizationsClient struct {
 	client *organizations.Client
 }
 
+// NewOrganizationsClient returns an OrganizationsClient whose requests, including
+// retries, go through a rate limiter that slows down on throttling errors.
 func NewOrganizationsClient(awscfg aws.Config) OrganizationsClient {
-	client := organizations.NewFromConfig(awscfg)
+	limiter := newRateLimiter(organizationsRequestsPerSecond)
+	client := organizations.NewFromConfig(awscfg, func(o *organizations.Options) {
+		o.APIOptions = append(o.APIOptions, limiter.addMiddleware)
+	})
 	return &organizationsClient{client: client}
 }
 
+var (
+	accountIdPattern            = regexp.MustCompile(`^\d{12}$`)
+	organizationalUnitIdPattern = regexp.MustCompile(`^ou-[0-9a-z]{4,32}-[a-z0-9]{8,32}$`)
+	rootIdPattern               = regexp.MustCompile(`^r-[0-9a-z]{4,32}$`)
+)
+
+// ValidAccountId reports whether accountId is a 12-digit AWS account ID.
+func ValidAccountId(accountId string) bool {
+	return accountIdPattern.MatchString(accountId)
+}
+
+// ValidOrganizationalUnitId reports whether id is an organizational unit ID
+// (ou-...) or an organization root ID (r-...).
+func ValidOrganizationalUnitId(id string) bool {
+	return organizationalUnitIdPattern.MatchString(id) || rootIdPattern.MatchString(id)
+}
+
+// Account statuses reported in OrganizationAccount.Status.
+const (
+	AccountStatusActive            = string(types.AccountStateActive)
+	AccountStatusSuspended         = string(types.AccountStateSuspended)
+	AccountStatusPendingClosure    = string(types.AccountStatePendingClosure)
+	AccountStatusPendingActivation = string(types.AccountStatePendingActivation)
+	AccountStatusClosed            = string(types.AccountStateClosed)
+)
+
 type OrganizationAccount struct {
 	Name      string
 	AccountID string
+	Status    string // Account state, such as AccountStatusActive or AccountStatusSuspended
+}
+
+// TagFilter matches accounts tagged with Key set to Value.
+type TagFilter struct {
+	Key   string
+	Value string
 }
 
-// GetNonManagementOrganizationAccounts fetches active organization accounts, excluding the management account.
+func (f TagFilter) String() string {
+	return f.Key + "=" + f.Value
+}
+
+// GetNonManagementOrganizationAccounts fetches organization accounts of any status, excluding the management account.
 func GetNonManagementOrganizationAccounts(ctx context.Context, org OrganizationsClient) ([]OrganizationAccount, error) {
 	slog.Debug("getting organization accounts")
 
@@ -48,6 +101,122 @@ func GetNonManagementOrganizationAccounts(ctx context.Context, org Organizations
 	return nonManagementAccounts, nil
 }
 
+// GetOrganizationalUnitAccounts fetches the accounts of any status under the given
+// roots or organizational units, excluding the management account. When recursive is set,
+// accounts in nested organizational units are included too.
+func GetOrganizationalUnitAccounts(ctx context.Context, org OrganizationsClient, parentIds []string, recursive bool) ([]OrganizationAccount, error) {
+	slog.Debug("getting organizational unit accounts", "parents", parentIds, "recursive", recursive)
+
+	for _, parentId := range parentIds {
+		if !ValidOrganizationalUnitId(parentId) {
+			return nil, fmt.Errorf("invalid organizational unit id %q: expected ou-... or r-...", parentId)
+		}
+	}
+
+	mgmAccountId, err := org.DescribeOrganization(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var accounts []OrganizationAccount
+	seen := make(map[string]bool)
+	pending := slices.Clone(parentIds)
+	for len(pending) > 0 {
+		parentId := pending[0]
+		pending = pending[1:]
+		if seen[parentId] {
+			continue
+		}
+		seen[parentId] = true
+
+		parentAccounts, err := org.ListAccountsForParent(ctx, parentId)
+		if err != nil {
+			return nil, err
+		}
+		for _, acc := range parentAccounts {
+			if acc.AccountID != mgmAccountId && !seen[acc.AccountID] {
+				seen[acc.AccountID] = true
+				accounts = append(accounts, acc)
+			}
+		}
+
+		if recursive {
+			children, err := org.ListChildOrganizationalUnits(ctx, parentId)
+			if err != nil {
+				return nil, err
+			}
+			pending = append(pending, children...)
+		}
+	}
+
+	return accounts, nil
+}
+
+// FilterAccountsByTags returns the accounts whose organization tags match all the
+// filters, or any of them when matchAny is set, keeping the order of accountIds.
+// The tags of at most concurrency accounts are looked up in parallel (values
+// lower than or equal to 0 use DefaultTagLookupConcurrency); the first lookup
+// error stops the remaining lookups.
+func FilterAccountsByTags(ctx context.Context, org OrganizationsClient, accountIds []string, filters []TagFilter, matchAny bool, concurrency int) ([]string, error) {
+	slog.Debug("filtering accounts by tags", "filters", filters, "match_any", matchAny, "concurrency", concurrency)
+	if concurrency <= 0 {
+		concurrency = DefaultTagLookupConcurrency
+	}
+
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	matches := make([]bool, len(accountIds))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for range min(concurrency, len(accountIds)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				tags, err := org.ListTagsForResource(ctx, accountIds[i])
+				if err != nil {
+					cancel(err)
+					continue
+				}
+				matches[i] = tagsMatch(tags, filters, matchAny)
+			}
+		}()
+	}
+schedule:
+	for i := range accountIds {
+		select {
+		case indexes <- i:
+		case <-ctx.Done():
+			break schedule
+		}
+	}
+	close(indexes)
+	wg.Wait()
+
+	if err := context.Cause(ctx); err != nil {
+		return nil, err
+	}
+	var matching []string
+	for i, accountId := range accountIds {
+		if matches[i] {
+			matching = append(matching, accountId)
+		}
+	}
+	return matching, nil
+}
+
+// tagsMatch reports whether tags match all the filters, or any of them when matchAny is set.
+func tagsMatch(tags map[string]string, filters []TagFilter, matchAny bool) bool {
+	matched := 0
+	for _, filter := range filters {
+		if value, ok := tags[filter.Key]; ok && value == filter.Value {
+			matched++
+		}
+	}
+	return (matchAny && matched > 0) || (!matchAny && matched == len(filters))
+}
+
 func (c *organizationsClient) DescribeOrganization(ctx context.Context) (string, error) {
 	slog.Debug("describing organization")
 
@@ -73,18 +242,79 @@ func (c *organizationsClient) ListAccounts(ctx context.Context) ([]OrganizationA
 			return nil, fmt.Errorf("failed to list organization accounts: %v", err)
 		}
 		for _, acc := range page.Accounts {
-			if acc.Status == types.AccountStatusActive {
-				accounts = append(accounts, OrganizationAccount{
-					Name:      aws.ToString(acc.Name),
-					AccountID: aws.ToString(acc.Id),
-				})
-			}
+			accounts = append(accounts, newOrganizationAccount(acc))
+		}
+	}
+
+	return accounts, nil
+}
+
+func (c *organizationsClient) ListAccountsForParent(ctx context.Context, parentId string) ([]OrganizationAccount, error) {
+	slog.Debug("listing accounts for parent", "parent_id", parentId)
+
+	params := &organizations.ListAccountsForParentInput{ParentId: aws.String(parentId)}
+	paginator := organizations.NewListAccountsForParentPaginator(c.client, params)
+
+	var accounts []OrganizationAccount
+
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list accounts for parent %s: %w", parentId, err)
+		}
+		for _, acc := range page.Accounts {
+			accounts = append(accounts, newOrganizationAccount(acc))
 		}
 	}
 
 	return accounts, nil
 }
 
+func (c *organizationsClient) ListChildOrganizationalUnits(ctx context.Context, parentId string) ([]string, error) {
+	slog.Debug("listing child organizational units", "parent_id", parentId)
+
+	params := &organizations.ListChildrenInput{
+		ParentId:  aws.String(parentId),
+		ChildType: types.ChildTypeOrganizationalUnit,
+	}
+	paginator := organizations.NewListChildrenPaginator(c.client, params)
+
+	var children []string
+
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list child organizational units of %s: %w", parentId, err)
+		}
+		for _, child := range page.Children {
+			children = append(children, aws.ToString(child.Id))
+		}
+	}
+
+	return children, nil
+}
+
+func (c *organizationsClient) ListTagsForResource(ctx context.Context, resourceId string) (map[string]string, error) {
+	slog.Debug("listing tags for resource", "resource_id", resourceId)
+
+	params := &organizations.ListTagsForResourceInput{ResourceId: aws.String(resourceId)}
+	paginator := organizations.NewListTagsForResourcePaginator(c.client, params)
+
+	tags := make(map[string]string)
+
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list tags for %s: %w", resourceId, err)
+		}
+		for _, tag := range page.Tags {
+			tags[aws.ToString(tag.Key)] = aws.ToString(tag.Value)
+		}
+	}
+
+	return tags, nil
+}
+
 func (c *organizationsClient) EnableAWSServiceAccess(ctx context.Context, service string) error {
 	slog.Debug("enabling service access", "service", service)
 
@@ -97,3 +327,17 @@ func (c *organizationsClient) EnableAWSServiceAccess(ctx context.Context, servic
 
 	return nil
 }
+
+// newOrganizationAccount converts an organizations account. The account state is
+// used when present, as the older status field is being retired.
+func newOrganizationAccount(acc types.Account) OrganizationAccount {
+	status := string(acc.State)
+	if status == "" {
+		status = string(acc.Status)
+	}
+	return OrganizationAccount{
+		Name:      aws.ToString(acc.Name),
+		AccountID: aws.ToString(acc.Id),
+		Status:    status,
+	}
+}
@@ -0,0 +1,107 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/organizations/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewOrganizationAccount(t *testing.T) {
+	tests := []struct {
+		name    string
+		account types.Account
+		want    OrganizationAccount
+	}{
+		{
+			name:    "state",
+			account: types.Account{Id: aws.String("111111111111"), Name: aws.String("sandbox"), State: types.AccountStatePendingClosure, Status: types.AccountStatusPendingClosure},
+			want:    OrganizationAccount{AccountID: "111111111111", Name: "sandbox", Status: AccountStatusPendingClosure},
+		},
+		{
+			name:    "status fallback",
+			account: types.Account{Id: aws.String("222222222222"), Name: aws.String("legacy"), Status: types.AccountStatusSuspended},
+			want:    OrganizationAccount{AccountID: "222222222222", Name: "legacy", Status: AccountStatusSuspended},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, newOrganizationAccount(tt.account))
+		})
+	}
+}
+
+func TestValidAccountId(t *testing.T) {
+	assert.True(t, ValidAccountId("123456789012"))
+	assert.False(t, ValidAccountId("12345678901"))
+	assert.False(t, ValidAccountId("1234567890123"))
+	assert.False(t, ValidAccountId("12345678901a"))
+	assert.False(t, ValidAccountId(""))
+}
+
+func TestValidOrganizationalUnitId(t *testing.T) {
+	assert.True(t, ValidOrganizationalUnitId("ou-abcd-12345678"))
+	assert.True(t, ValidOrganizationalUnitId("r-abcd"))
+	assert.False(t, ValidOrganizationalUnitId("ou-legacy"))
+	assert.False(t, ValidOrganizationalUnitId("r-and-d"))
+	assert.False(t, ValidOrganizationalUnitId("ou-ABCD-12345678"))
+	assert.False(t, ValidOrganizationalUnitId("sandbox"))
+}
+
+// tagsOrganizationsClient implements OrganizationsClient for tag lookups, recording
+// how many lookups run at once.
+type tagsOrganizationsClient struct {
+	OrganizationsClient
+
+	tags    map[string]map[string]string
+	tagErrs map[string]error
+
+	inFlight    atomic.Int32
+	mu          sync.Mutex
+	maxInFlight int32
+}
+
+func (c *tagsOrganizationsClient) ListTagsForResource(_ context.Context, resourceId string) (map[string]string, error) {
+	inFlight := c.inFlight.Add(1)
+	defer c.inFlight.Add(-1)
+	c.mu.Lock()
+	c.maxInFlight = max(c.maxInFlight, inFlight)
+	c.mu.Unlock()
+	time.Sleep(time.Millisecond)
+	return c.tags[resourceId], c.tagErrs[resourceId]
+}
+
+func TestFilterAccountsByTags_Concurrency(t *testing.T) {
+	org := &tagsOrganizationsClient{tags: make(map[string]map[string]string)}
+	var accountIds, want []string
+	for i := range 20 {
+		accountId := fmt.Sprintf("%012d", i)
+		accountIds = append(accountIds, accountId)
+		if i%2 == 0 {
+			org.tags[accountId] = map[string]string{"env": "sandbox"}
+			want = append(want, accountId)
+		}
+	}
+
+	matching, err := FilterAccountsByTags(context.Background(), org, accountIds, []TagFilter{{Key: "env", Value: "sandbox"}}, false, 3)
+	require.NoError(t, err)
+	assert.Equal(t, want, matching)
+	assert.LessOrEqual(t, org.maxInFlight, int32(3))
+}
+
+func TestFilterAccountsByTags_Error(t *testing.T) {
+	tagErr := errors.New("throttled")
+	org := &tagsOrganizationsClient{tagErrs: map[string]error{"000000000001": tagErr}}
+
+	_, err := FilterAccountsByTags(context.Background(), org, []string{"000000000000", "000000000001", "000000000002"}, []TagFilter{{Key: "env", Value: "sandbox"}}, true, 2)
+	assert.ErrorIs(t, err, tagErr)
+}
@@ -0,0 +1,131 @@
+package aws
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	"github.com/aws/smithy-go/middleware"
+)
+
+// minRequestsPerSecond is the lowest rate the limiter backs off to.
+const minRequestsPerSecond = 0.1
+
+// rateLimiter is a token bucket whose refill rate adapts to throttling:
+// it halves on every throttling error and recovers gradually on success,
+// up to the configured rate. The bucket holds up to one second of tokens.
+type rateLimiter struct {
+	mu      sync.Mutex
+	rate    float64 // current tokens per second
+	maxRate float64 // configured tokens per second
+	tokens  float64
+	last    time.Time
+
+	now   func() time.Time
+	sleep func(ctx context.Context, d time.Duration) error
+}
+
+func newRateLimiter(requestsPerSecond float64) *rateLimiter {
+	return &rateLimiter{
+		rate:    requestsPerSecond,
+		maxRate: requestsPerSecond,
+		tokens:  max(1, requestsPerSecond),
+		last:    time.Now(),
+		now:     time.Now,
+		sleep:   sleepContext,
+	}
+}
+
+// sleepContext waits for d, or returns the error of ctx if it is done first.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Wait blocks until a token is available or ctx is done.
+func (l *rateLimiter) Wait(ctx context.Context) error {
+	l.mu.Lock()
+	l.refill(l.now())
+	l.tokens--
+	var delay time.Duration
+	if l.tokens < 0 {
+		delay = time.Duration(-l.tokens / l.rate * float64(time.Second))
+	}
+	l.mu.Unlock()
+
+	if delay == 0 {
+		return nil
+	}
+
+	if err := l.sleep(ctx, delay); err != nil {
+		// give back the reserved token
+		l.mu.Lock()
+		l.tokens++
+		l.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// Throttled halves the rate after a throttling error.
+func (l *rateLimiter) Throttled() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.refill(l.now())
+	l.rate = max(minRequestsPerSecond, l.rate/2)
+	l.tokens = min(l.tokens, max(1, l.rate))
+	slog.Debug("request throttled, reducing rate", "requests_per_second", l.rate)
+}
+
+// Succeeded increases the rate by a tenth of the configured rate, up to the configured rate.
+func (l *rateLimiter) Succeeded() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.rate < l.maxRate {
+		l.refill(l.now())
+		l.rate = min(l.maxRate, l.rate+l.maxRate/10)
+	}
+}
+
+// refill adds the tokens accumulated since the last refill. Must be called with mu held.
+func (l *rateLimiter) refill(now time.Time) {
+	elapsed := now.Sub(l.last).Seconds()
+	l.tokens = min(max(1, l.rate), l.tokens+elapsed*l.rate)
+	l.last = now
+}
+
+// ID implements middleware.FinalizeMiddleware.
+func (l *rateLimiter) ID() string {
+	return "RateLimiter"
+}
+
+// HandleFinalize waits for a token before every request attempt, including
+// retries, and adapts the rate to the attempt outcome.
+func (l *rateLimiter) HandleFinalize(ctx context.Context, in middleware.FinalizeInput, next middleware.FinalizeHandler) (middleware.FinalizeOutput, middleware.Metadata, error) {
+	if err := l.Wait(ctx); err != nil {
+		return middleware.FinalizeOutput{}, middleware.Metadata{}, err
+	}
+
+	out, metadata, err := next.HandleFinalize(ctx, in)
+	switch {
+	case err == nil:
+		l.Succeeded()
+	case retry.IsErrorThrottles(retry.DefaultThrottles).IsErrorThrottle(err) == aws.TrueTernary:
+		l.Throttled()
+	}
+	return out, metadata, err
+}
+
+// addMiddleware registers the limiter inside the retry loop so every attempt is rate limited.
+func (l *rateLimiter) addMiddleware(stack *middleware.Stack) error {
+	return stack.Finalize.Insert(l, "Retry", middleware.After)
+}
@@ -0,0 +1,95 @@
+package aws
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newFakeClockRateLimiter returns a limiter whose clock only moves when the
+// returned function is called, and that records its waits instead of sleeping.
+func newFakeClockRateLimiter(requestsPerSecond float64) (*rateLimiter, func(time.Duration), *[]time.Duration) {
+	limiter := newRateLimiter(requestsPerSecond)
+	now := limiter.last
+	limiter.now = func() time.Time { return now }
+	var waits []time.Duration
+	limiter.sleep = func(_ context.Context, d time.Duration) error {
+		waits = append(waits, d)
+		return nil
+	}
+	return limiter, func(d time.Duration) { now = now.Add(d) }, &waits
+}
+
+func TestRateLimiter_BurstThenWait(t *testing.T) {
+	limiter, advance, waits := newFakeClockRateLimiter(20)
+	ctx := context.Background()
+
+	// a full bucket allows a burst of one second of requests without waiting
+	for range 20 {
+		require.NoError(t, limiter.Wait(ctx))
+	}
+	assert.Empty(t, *waits)
+	assert.InDelta(t, 0, limiter.tokens, 0.001)
+
+	// the next request waits for one token to refill
+	require.NoError(t, limiter.Wait(ctx))
+	assert.Equal(t, []time.Duration{50 * time.Millisecond}, *waits)
+	assert.InDelta(t, -1, limiter.tokens, 0.001)
+
+	// tokens refill at the rate, up to one second of tokens
+	advance(100 * time.Millisecond)
+	require.NoError(t, limiter.Wait(ctx))
+	assert.Len(t, *waits, 1)
+	assert.InDelta(t, 0, limiter.tokens, 0.001)
+
+	advance(time.Hour)
+	require.NoError(t, limiter.Wait(ctx))
+	assert.InDelta(t, 19, limiter.tokens, 0.001)
+}
+
+func TestRateLimiter_ThrottledWait(t *testing.T) {
+	limiter, _, waits := newFakeClockRateLimiter(4)
+	ctx := context.Background()
+
+	// halving the rate caps the bucket and doubles the wait per token
+	limiter.Throttled()
+	assert.InDelta(t, 2, limiter.tokens, 0.001)
+	for range 3 {
+		require.NoError(t, limiter.Wait(ctx))
+	}
+	assert.Equal(t, []time.Duration{500 * time.Millisecond}, *waits)
+}
+
+func TestRateLimiter_WaitCancelled(t *testing.T) {
+	limiter := newRateLimiter(1)
+	require.NoError(t, limiter.Wait(context.Background()))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.ErrorIs(t, limiter.Wait(ctx), context.Canceled)
+}
+
+func TestRateLimiter_AdaptsToThrottling(t *testing.T) {
+	limiter := newRateLimiter(10)
+
+	limiter.Throttled()
+	assert.InDelta(t, 5, limiter.rate, 0.001)
+	limiter.Throttled()
+	assert.InDelta(t, 2.5, limiter.rate, 0.001)
+
+	for range 20 {
+		limiter.Succeeded()
+	}
+	assert.InDelta(t, 10, limiter.rate, 0.001)
+}
+
+func TestRateLimiter_MinimumRate(t *testing.T) {
+	limiter := newRateLimiter(1)
+	for range 10 {
+		limiter.Throttled()
+	}
+	assert.InDelta(t, minRequestsPerSecond, limiter.rate, 0.001)
+}
@@ -0,0 +1,54 @@
+package aws
+
+import (
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+)
+
+// sessionExpiryWindow is how long before their expiration cached root sessions
+// stop being reused, so callers always get some time to use them.
+const sessionExpiryWindow = 15 * time.Second
+
+// sessionKey identifies an AssumeRoot session.
+type sessionKey struct {
+	accountId      string
+	taskPolicyName string
+}
+
+// sessionCache keeps the AssumeRoot credentials obtained during a run, so that
+// operations on the same account and task policy reuse the session instead of
+// calling sts:AssumeRoot again. Sessions close to expiry are not returned.
+type sessionCache struct {
+	mu       sync.Mutex
+	sessions map[sessionKey]aws.Credentials
+	now      func() time.Time
+}
+
+func newSessionCache() *sessionCache {
+	return &sessionCache{sessions: make(map[sessionKey]aws.Credentials), now: time.Now}
+}
+
+// get returns the cached credentials for key if they are still valid.
+func (c *sessionCache) get(key sessionKey) (aws.Credentials, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	creds, ok := c.sessions[key]
+	if !ok {
+		return aws.Credentials{}, false
+	}
+	if creds.CanExpire && !creds.Expires.After(c.now().Add(sessionExpiryWindow)) {
+		delete(c.sessions, key)
+		return aws.Credentials{}, false
+	}
+	return creds, true
+}
+
+// put stores the credentials of a new session for key.
+func (c *sessionCache) put(key sessionKey, creds aws.Credentials) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.sessions[key] = creds
+}
@@ -0,0 +1,37 @@
+package aws
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSessionCache_ReturnsValidSession(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	cache := newSessionCache()
+	cache.now = func() time.Time { return now }
+	key := sessionKey{accountId: "123456789012", taskPolicyName: "IAMAuditRootUserCredentials"}
+
+	cache.put(key, aws.Credentials{AccessKeyID: "ASIA1", CanExpire: true, Expires: now.Add(time.Minute)})
+
+	creds, ok := cache.get(key)
+	assert.True(t, ok)
+	assert.Equal(t, "ASIA1", creds.AccessKeyID)
+
+	_, ok = cache.get(sessionKey{accountId: "123456789012", taskPolicyName: "IAMDeleteRootUserCredentials"})
+	assert.False(t, ok)
+}
+
+func TestSessionCache_SkipsSessionsCloseToExpiry(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	cache := newSessionCache()
+	cache.now = func() time.Time { return now }
+	key := sessionKey{accountId: "123456789012", taskPolicyName: "IAMAuditRootUserCredentials"}
+
+	cache.put(key, aws.Credentials{AccessKeyID: "ASIA1", CanExpire: true, Expires: now.Add(sessionExpiryWindow - time.Second)})
+
+	_, ok := cache.get(key)
+	assert.False(t, ok)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/sts"
@@ -12,39 +13,141 @@ import (
 
 const rootPolicyPrefix = "arn:aws:iam::aws:policy/root-task/"
 
+const (
+	// DefaultAssumeRootDuration is the default duration of AssumeRoot sessions.
+	DefaultAssumeRootDuration = time.Minute
+
+	// MinAssumeRootDuration is the minimum duration of AssumeRoot sessions. Shorter
+	// sessions would be close to sessionExpiryWindow and renewed on almost every call.
+	MinAssumeRootDuration = time.Minute
+
+	// MaxAssumeRootDuration is the maximum duration AWS allows for AssumeRoot sessions.
+	MaxAssumeRootDuration = 15 * time.Minute
+)
+
 type stsClient struct {
-	client *sts.Client
+	client   *sts.Client
+	limiter  *rateLimiter // nil when rate limiting is disabled
+	baseCfg  aws.Config   // config root configs are derived from
+	sessions *sessionCache
+	duration time.Duration
+}
+
+type stsClientOptions struct {
+	requestsPerSecond float64
+	rootBaseCfg       *aws.Config
+	sessionDuration   time.Duration
+}
+
+// StsClientOption configures the client created by NewStsClient.
+type StsClientOption func(*stsClientOptions)
+
+// WithRateLimit limits AssumeRoot attempts, including retries, to requestsPerSecond
+// using a token bucket that slows down on throttling errors. A value of 0 disables it.
+func WithRateLimit(requestsPerSecond float64) StsClientOption {
+	return func(o *stsClientOptions) {
+		o.requestsPerSecond = requestsPerSecond
+	}
 }
 
-func NewStsClient(awscfg aws.Config) StsClient {
-	client := sts.NewFromConfig(awscfg)
-	return &stsClient{client: client}
+// WithRootBaseConfig derives the configs returned by GetAssumeRootConfig from
+// awscfg instead of from the config of the STS client.
+func WithRootBaseConfig(awscfg aws.Config) StsClientOption {
+	return func(o *stsClientOptions) {
+		o.rootBaseCfg = &awscfg
+	}
+}
+
+// WithSessionDuration sets the duration of AssumeRoot sessions, between
+// MinAssumeRootDuration and MaxAssumeRootDuration. Values lower than or equal
+// to 0 use DefaultAssumeRootDuration.
+func WithSessionDuration(duration time.Duration) StsClientOption {
+	return func(o *stsClientOptions) {
+		o.sessionDuration = duration
+	}
+}
+
+func NewStsClient(awscfg aws.Config, opts ...StsClientOption) StsClient {
+	var options stsClientOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if options.sessionDuration <= 0 {
+		options.sessionDuration = DefaultAssumeRootDuration
+	}
+	options.sessionDuration = min(max(options.sessionDuration, MinAssumeRootDuration), MaxAssumeRootDuration)
+
+	var limiter *rateLimiter
+	var stsOptions []func(*sts.Options)
+	if options.requestsPerSecond > 0 {
+		limiter = newRateLimiter(options.requestsPerSecond)
+		stsOptions = append(stsOptions, func(o *sts.Options) {
+			o.APIOptions = append(o.APIOptions, limiter.addMiddleware)
+		})
+	}
+
+	baseCfg := awscfg
+	if options.rootBaseCfg != nil {
+		baseCfg = *options.rootBaseCfg
+	}
+
+	client := sts.NewFromConfig(awscfg, stsOptions...)
+	return &stsClient{client: client, limiter: limiter, baseCfg: baseCfg, sessions: newSessionCache(), duration: options.sessionDuration}
 }
 
+// GetAssumeRootConfig returns a config with root credentials for the account,
+// limited to the task policy. Sessions are cached per account and task policy
+// for the lifetime of the client and reused until they are about to expire.
+// The config credentials assume root again before they expire, so operations
+// can outlive a single session.
 func (c *stsClient) GetAssumeRootConfig(ctx context.Context, accountId, taskPolicyName string) (aws.Config, error) {
 	slog.Debug("getting root aws config", "account_id", accountId, "task", taskPolicyName)
 
-	stsCreds, err := c.assumeRoot(ctx, accountId, taskPolicyName)
-	if err != nil {
+	// assume root upfront so AssumeRoot errors are reported here rather than on first use
+	provider := &rootCredentialsProvider{client: c, key: sessionKey{accountId: accountId, taskPolicyName: taskPolicyName}}
+	if _, err := provider.Retrieve(ctx); err != nil {
 		return aws.Config{}, err
 	}
 
-	// Convert sts.Credentials to aws.Credentials
-	awsCreds := aws.Credentials{
-		AccessKeyID:     aws.ToString(stsCreds.AccessKeyId),
-		SecretAccessKey: aws.ToString(stsCreds.SecretAccessKey),
-		SessionToken:    aws.ToString(stsCreds.SessionToken),
-	}
+	awsrootcfg := c.baseCfg.Copy()
+	awsrootcfg.Credentials = aws.NewCredentialsCache(provider, func(o *aws.CredentialsCacheOptions) {
+		o.ExpiryWindow = sessionExpiryWindow
+	})
+
+	return awsrootcfg, nil
+}
 
-	awsrootcfg, err := LoadAWSConfig(ctx, WithCredentials(&awsCreds))
+// rootCredentialsProvider provides the credentials of an AssumeRoot session,
+// reusing the cached session while it is valid and assuming root again otherwise.
+type rootCredentialsProvider struct {
+	client *stsClient
+	key    sessionKey
+}
 
+func (p *rootCredentialsProvider) Retrieve(ctx context.Context) (aws.Credentials, error) {
+	if creds, ok := p.client.sessions.get(p.key); ok {
+		slog.Debug("reusing cached assume root session", "account_id", p.key.accountId, "task", p.key.taskPolicyName)
+		return creds, nil
+	}
+
+	stsCreds, err := p.client.assumeRoot(ctx, p.key.accountId, p.key.taskPolicyName)
 	if err != nil {
-		return aws.Config{}, fmt.Errorf("error loading aws root config: %s", err)
+		return aws.Credentials{}, err
 	}
 
-	slog.Debug("successfully generated assume root credentials", "account_id", accountId, "task", taskPolicyName)
+	// Convert sts.Credentials to aws.Credentials
+	creds := aws.Credentials{
+		AccessKeyID:     aws.ToString(stsCreds.AccessKeyId),
+		SecretAccessKey: aws.ToString(stsCreds.SecretAccessKey),
+		SessionToken:    aws.ToString(stsCreds.SessionToken),
+		Source:          "AssumeRoot",
+		CanExpire:       stsCreds.Expiration != nil,
+		Expires:         aws.ToTime(stsCreds.Expiration),
+	}
+	p.client.sessions.put(p.key, creds)
 
-	return awsrootcfg, nil
+	slog.Debug("successfully generated assume root credentials", "account_id", p.key.accountId, "task", p.key.taskPolicyName, "expires", creds.Expires)
+	return creds, nil
 }
 
 func (c *stsClient) assumeRoot(ctx context.Context, accountId, taskPolicyName string) (types.Credentials, error) {
@@ -55,7 +158,7 @@ func (c *stsClient) assumeRoot(ctx context.Context, accountId, taskPolicyName st
 		TaskPolicyArn: &types.PolicyDescriptorType{
 			Arn: aws.String(rootPolicyPrefix + taskPolicyName),
 		},
-		DurationSeconds: aws.Int32(60),
+		DurationSeconds: aws.Int32(int32(c.duration.Seconds())),
 	}
 
 	output, err := c.client.AssumeRoot(ctx, params)
@@ -0,0 +1,127 @@
+package aws
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStsClient_RateLimitBacksOffOnThrottling(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`<ErrorResponse><Error><Type>Sender</Type><Code>Throttling</Code><Message>Rate exceeded</Message></Error></ErrorResponse>`))
+	}))
+	defer server.Close()
+
+	cfg := aws.Config{
+		Region:       defaultRegion,
+		BaseEndpoint: aws.String(server.URL),
+		Credentials:  credentials.NewStaticCredentialsProvider("akid", "secret", ""),
+		Retryer:      func() aws.Retryer { return aws.NopRetryer{} },
+	}
+	client := NewStsClient(cfg, WithRateLimit(8)).(*stsClient)
+	require.NotNil(t, client.limiter)
+
+	_, err := client.GetAssumeRootConfig(context.Background(), "123456789012", "IAMAuditRootUserCredentials")
+	require.Error(t, err)
+	assert.InDelta(t, 4, client.limiter.rate, 0.001)
+}
+
+func TestStsClient_RateLimitDisabled(t *testing.T) {
+	client := NewStsClient(aws.Config{Region: defaultRegion}).(*stsClient)
+	assert.Nil(t, client.limiter)
+}
+
+// newAssumeRootServer returns a test STS endpoint answering AssumeRoot with
+// credentials expiring after expiresIn, and the requests it received.
+func newAssumeRootServer(t *testing.T, expiresIn time.Duration) (*httptest.Server, *[]url.Values) {
+	t.Helper()
+	var requests []url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		requests = append(requests, r.PostForm)
+		w.Header().Set("Content-Type", "text/xml")
+		w.Write([]byte(`<AssumeRootResponse xmlns="https://sts.amazonaws.com/doc/2011-06-15/"><AssumeRootResult><Credentials>` +
+			`<AccessKeyId>ASIAROOT</AccessKeyId><SecretAccessKey>secret</SecretAccessKey><SessionToken>token</SessionToken>` +
+			`<Expiration>` + time.Now().Add(expiresIn).UTC().Format(time.RFC3339) + `</Expiration>` +
+			`</Credentials></AssumeRootResult><ResponseMetadata><RequestId>id</RequestId></ResponseMetadata></AssumeRootResponse>`))
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func testStsConfig(endpoint string) aws.Config {
+	return aws.Config{
+		Region:       defaultRegion,
+		BaseEndpoint: aws.String(endpoint),
+		Credentials:  credentials.NewStaticCredentialsProvider("akid", "secret", ""),
+		Retryer:      func() aws.Retryer { return aws.NopRetryer{} },
+	}
+}
+
+func TestStsClient_ReusesCachedSession(t *testing.T) {
+	server, requests := newAssumeRootServer(t, time.Hour)
+	base := aws.Config{Region: "eu-west-1"}
+	client := NewStsClient(testStsConfig(server.URL), WithRootBaseConfig(base))
+
+	rootCfg, err := client.GetAssumeRootConfig(context.Background(), "123456789012", "IAMAuditRootUserCredentials")
+	require.NoError(t, err)
+	_, err = client.GetAssumeRootConfig(context.Background(), "123456789012", "IAMAuditRootUserCredentials")
+	require.NoError(t, err)
+	assert.Len(t, *requests, 1)
+
+	_, err = client.GetAssumeRootConfig(context.Background(), "123456789012", "IAMDeleteRootUserCredentials")
+	require.NoError(t, err)
+	assert.Len(t, *requests, 2)
+
+	assert.Equal(t, "eu-west-1", rootCfg.Region)
+	creds, err := rootCfg.Credentials.Retrieve(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "ASIAROOT", creds.AccessKeyID)
+	assert.Len(t, *requests, 2)
+}
+
+func TestStsClient_RefreshesExpiringSession(t *testing.T) {
+	server, requests := newAssumeRootServer(t, sessionExpiryWindow/2)
+	client := NewStsClient(testStsConfig(server.URL))
+
+	rootCfg, err := client.GetAssumeRootConfig(context.Background(), "123456789012", "S3UnlockBucketPolicy")
+	require.NoError(t, err)
+	require.Len(t, *requests, 1)
+
+	_, err = rootCfg.Credentials.Retrieve(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, *requests, 2)
+}
+
+func TestStsClient_SessionDuration(t *testing.T) {
+	tests := []struct {
+		name     string
+		duration time.Duration
+		want     string
+	}{
+		{"default", 0, "60"},
+		{"configured", 10 * time.Minute, "600"},
+		{"raised to minimum", 500 * time.Millisecond, "60"},
+		{"capped at maximum", time.Hour, "900"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, requests := newAssumeRootServer(t, time.Hour)
+			client := NewStsClient(testStsConfig(server.URL), WithSessionDuration(tt.duration))
+
+			_, err := client.GetAssumeRootConfig(context.Background(), "123456789012", "IAMAuditRootUserCredentials")
+			require.NoError(t, err)
+			require.Len(t, *requests, 1)
+			assert.Equal(t, tt.want, (*requests)[0].Get("DurationSeconds"))
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package ui
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/unicrons/aws-root-manager/internal/aws"
+)
+
+// StdinAccountsFile is the accounts file path that reads account IDs from stdin.
+const StdinAccountsFile = "-"
+
+// ReadAccountsFile reads the account IDs in the file at path, or in stdin when
+// path is StdinAccountsFile. See ParseAccountsList for the accepted formats.
+func ReadAccountsFile(path string, stdin io.Reader) ([]string, error) {
+	var data []byte
+	var err error
+	if path == StdinAccountsFile {
+		data, err = io.ReadAll(stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read accounts file: %w", err)
+	}
+
+	accounts, err := ParseAccountsList(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid accounts file: %w", err)
+	}
+	return accounts, nil
+}
+
+// ParseAccountsList parses account IDs given as a json array of strings, or as
+// one account ID per line where blank lines and lines starting with # are
+// ignored. Every invalid entry is reported, and duplicates are removed.
+func ParseAccountsList(data []byte) ([]string, error) {
+	var entries []string
+	var positions []string // where each entry is, for error messages
+	if trimmed := bytes.TrimSpace(data); bytes.HasPrefix(trimmed, []byte("[")) {
+		if err := json.Unmarshal(trimmed, &entries); err != nil {
+			return nil, fmt.Errorf("failed to decode json array: %w", err)
+		}
+		for i := range entries {
+			positions = append(positions, fmt.Sprintf("entry %d", i+1))
+		}
+	} else {
+		for i, line := range strings.Split(string(data), "\n") {
+			line = strings.TrimSpace(line)
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+			entries = append(entries, line)
+			positions = append(positions, fmt.Sprintf("line %d", i+1))
+		}
+	}
+
+	var accounts []string
+	var errs []error
+	for i, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if !aws.ValidAccountId(entry) {
+			errs = append(errs, fmt.Errorf("%s: invalid account ID %q", positions[i], entry))
+			continue
+		}
+		if !slices.Contains(accounts, entry) {
+			accounts = append(accounts, entry)
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	if len(accounts) == 0 {
+		return nil, errors.New("no account IDs found")
+	}
+	return accounts, nil
+}
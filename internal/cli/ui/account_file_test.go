@@ -0,0 +1,81 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAccountsList(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    []string
+		wantErr []string
+	}{
+		{
+			name: "one per line",
+			data: "# sandbox accounts\n111111111111\n\n  222222222222  \r\n111111111111\n",
+			want: []string{"111111111111", "222222222222"},
+		},
+		{
+			name: "json array",
+			data: ` ["111111111111", "222222222222", "111111111111"]`,
+			want: []string{"111111111111", "222222222222"},
+		},
+		{
+			name:    "invalid lines",
+			data:    "111111111111\n11111111111\nsandbox\n",
+			wantErr: []string{`line 2: invalid account ID "11111111111"`, `line 3: invalid account ID "sandbox"`},
+		},
+		{
+			name:    "invalid json entries",
+			data:    `["111111111111", "2222-2222-2222"]`,
+			wantErr: []string{`entry 2: invalid account ID "2222-2222-2222"`},
+		},
+		{
+			name:    "malformed json",
+			data:    `["111111111111",`,
+			wantErr: []string{"failed to decode json array"},
+		},
+		{
+			name:    "empty",
+			data:    "# nothing here\n",
+			wantErr: []string{"no account IDs found"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseAccountsList([]byte(tt.data))
+			if len(tt.wantErr) > 0 {
+				for _, want := range tt.wantErr {
+					assert.ErrorContains(t, err, want)
+				}
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestReadAccountsFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "accounts.txt")
+	require.NoError(t, os.WriteFile(path, []byte("111111111111\n222222222222\n"), 0o600))
+
+	accounts, err := ReadAccountsFile(path, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"111111111111", "222222222222"}, accounts)
+
+	accounts, err = ReadAccountsFile(StdinAccountsFile, strings.NewReader(`["333333333333"]`))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"333333333333"}, accounts)
+
+	_, err = ReadAccountsFile(filepath.Join(t.TempDir(), "missing.txt"), nil)
+	assert.ErrorContains(t, err, "failed to read accounts file")
+}
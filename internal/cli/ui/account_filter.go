@@ -0,0 +1,135 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"slices"
+
+	"github.com/unicrons/aws-root-manager/internal/aws"
+)
+
+// accountFilter drops the accounts excluded by ID, organizational unit or name
+// pattern, and the accounts whose name does not match the name pattern.
+type accountFilter struct {
+	excludeIds   []string
+	excludeOUs   []string
+	excludeNames []*regexp.Regexp
+	nameMatch    *regexp.Regexp
+
+	// accounts under excludeOUs, resolved by resolve
+	ouAccounts map[string]bool
+}
+
+// newAccountFilter parses the exclusions and name pattern of selection. Exclusions
+// are account IDs (12 digits), organizational unit or root IDs (ou-xxxx-xxxxxxxx
+// or r-xxxx), or regular expressions matched against account names.
+func newAccountFilter(selection AccountSelection) (*accountFilter, error) {
+	f := &accountFilter{}
+	for _, exclude := range selection.Exclude {
+		switch {
+		case aws.ValidAccountId(exclude):
+			f.excludeIds = append(f.excludeIds, exclude)
+		case aws.ValidOrganizationalUnitId(exclude):
+			f.excludeOUs = append(f.excludeOUs, exclude)
+		default:
+			pattern, err := regexp.Compile(exclude)
+			if err != nil {
+				return nil, fmt.Errorf("invalid exclude pattern %q: %w", exclude, err)
+			}
+			f.excludeNames = append(f.excludeNames, pattern)
+		}
+	}
+	if selection.NameMatch != "" {
+		pattern, err := regexp.Compile(selection.NameMatch)
+		if err != nil {
+			return nil, fmt.Errorf("invalid name pattern %q: %w", selection.NameMatch, err)
+		}
+		f.nameMatch = pattern
+	}
+	return f, nil
+}
+
+// empty reports whether the filter keeps every account.
+func (f *accountFilter) empty() bool {
+	return len(f.excludeIds) == 0 && len(f.excludeOUs) == 0 && !f.matchesNames()
+}
+
+// matchesNames reports whether the filter needs account names.
+func (f *accountFilter) matchesNames() bool {
+	return len(f.excludeNames) > 0 || f.nameMatch != nil
+}
+
+// resolve fetches the accounts of the excluded organizational units, including
+// nested organizational units.
+func (f *accountFilter) resolve(ctx context.Context, org aws.OrganizationsClient) error {
+	if len(f.excludeOUs) == 0 {
+		return nil
+	}
+	accounts, err := aws.GetOrganizationalUnitAccounts(ctx, org, f.excludeOUs, true)
+	if err != nil {
+		return fmt.Errorf("error fetching excluded organizational unit accounts: %w", err)
+	}
+	f.ouAccounts = make(map[string]bool, len(accounts))
+	for _, account := range accounts {
+		f.ouAccounts[account.AccountID] = true
+	}
+	return nil
+}
+
+// keep reports whether the account passes the filter. resolve must be called first.
+func (f *accountFilter) keep(account aws.OrganizationAccount) bool {
+	if slices.Contains(f.excludeIds, account.AccountID) || f.ouAccounts[account.AccountID] {
+		return false
+	}
+	for _, pattern := range f.excludeNames {
+		if pattern.MatchString(account.Name) {
+			return false
+		}
+	}
+	return f.nameMatch == nil || f.nameMatch.MatchString(account.Name)
+}
+
+// apply returns the accounts that pass the filter, keeping their order.
+func (f *accountFilter) apply(ctx context.Context, org aws.OrganizationsClient, accounts []aws.OrganizationAccount) ([]aws.OrganizationAccount, error) {
+	if f.empty() {
+		return accounts, nil
+	}
+	if err := f.resolve(ctx, org); err != nil {
+		return nil, err
+	}
+
+	var kept []aws.OrganizationAccount
+	for _, account := range accounts {
+		if f.keep(account) {
+			kept = append(kept, account)
+		} else {
+			slog.Debug("account excluded", "account_id", account.AccountID, "name", account.Name)
+		}
+	}
+	return kept, nil
+}
+
+// applyIds returns the account IDs that pass the filter, looking up account
+// names in orgAccounts.
+func (f *accountFilter) applyIds(ctx context.Context, org aws.OrganizationsClient, orgAccounts []aws.OrganizationAccount, accountIds []string) ([]string, error) {
+	if f.empty() {
+		return accountIds, nil
+	}
+
+	names := make(map[string]string, len(orgAccounts))
+	for _, account := range orgAccounts {
+		names[account.AccountID] = account.Name
+	}
+
+	accounts := make([]aws.OrganizationAccount, len(accountIds))
+	for i, accountId := range accountIds {
+		accounts[i] = aws.OrganizationAccount{AccountID: accountId, Name: names[accountId]}
+	}
+	kept, err := f.apply(ctx, org, accounts)
+	if err != nil {
+		return nil, err
+	}
+	return convertAccountsToIDs(kept), nil
+}
@@ -2,8 +2,11 @@ package ui
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"slices"
+	"strings"
 
 	"github.com/unicrons/aws-root-manager/internal/aws"
 )
@@ -13,33 +16,118 @@ const (
 	AllAccountsSelectorText = "All Accounts"
 )
 
-// SelectTargetAccounts handles interactive account selection or returns accounts based on flags.
-// Returns account IDs based on flags or TUI prompt.
-// org is only used when accountsFlag is empty or "all".
-func SelectTargetAccounts(ctx context.Context, org aws.OrganizationsClient, accountsFlag []string) ([]string, error) {
-	slog.Debug("processing target accounts", "accounts_flag", accountsFlag)
+// AccountSelection describes the target accounts requested through flags.
+type AccountSelection struct {
+	Accounts  []string // Explicit account IDs, or AllAccountsOption
+	FromFile  []string // Account IDs read from an accounts file, which must be organization member accounts
+	OUs       []string // Organizational units (or roots) whose accounts are targeted
+	Recursive bool     // Whether accounts in nested organizational units are targeted too
 
-	// if accounts are provided and "all" is not specified, return them
-	if len(accountsFlag) > 0 && accountsFlag[0] != AllAccountsOption {
-		return accountsFlag, nil
-	}
+	Tags        []aws.TagFilter // Organization tags the target accounts must have
+	MatchAnyTag bool            // Whether matching any of Tags is enough instead of all of them
+
+	Exclude   []string // Account IDs, organizational units or account name patterns to skip
+	NameMatch string   // Pattern the names of the target accounts must match
+
+	IncludeStatus []string // Account statuses targeted in addition to aws.AccountStatusActive
+
+	Concurrency int // Maximum number of accounts whose tags are looked up in parallel
+}
 
-	// fetch all non-management accounts
+// targetable reports whether accounts with status can be targeted.
+func (s AccountSelection) targetable(status string) bool {
+	return status == aws.AccountStatusActive || slices.Contains(s.IncludeStatus, status)
+}
+
+// targetableAccounts keeps the accounts whose status can be targeted.
+func (s AccountSelection) targetableAccounts(accounts []aws.OrganizationAccount) []aws.OrganizationAccount {
+	return slices.DeleteFunc(slices.Clone(accounts), func(account aws.OrganizationAccount) bool {
+		return !s.targetable(account.Status)
+	})
+}
+
+// ListOrganizationAccounts lists the member accounts of the organization, of any
+// status and excluding the management account. Commands list them once and pass
+// them to SelectTargetAccounts and SelectSingleTargetAccount.
+func ListOrganizationAccounts(ctx context.Context, org aws.OrganizationsClient) ([]aws.OrganizationAccount, error) {
 	orgAccounts, err := aws.GetNonManagementOrganizationAccounts(ctx, org)
 	if err != nil {
 		return nil, fmt.Errorf("error fetching organization accounts: %w", err)
 	}
+	return orgAccounts, nil
+}
 
-	// if "all" is specified, return all account IDs
-	if len(accountsFlag) > 0 && accountsFlag[0] == AllAccountsOption {
-		return convertAccountsToIDs(orgAccounts), nil
+// SelectTargetAccounts handles interactive account selection or returns accounts based on flags.
+// Returns account IDs based on flags or TUI prompt: explicit accounts and accounts
+// read from a file followed by the accounts of the selected organizational units,
+// without duplicates. Tag filters, exclusions and the name pattern narrow down
+// these accounts, or all accounts when no other flag is given. Only active
+// accounts are targeted in organizational units and the organization, unless
+// their status is included in the selection; explicit accounts are not checked.
+// orgAccounts are the accounts returned by ListOrganizationAccounts. org is only
+// used to resolve organizational units and tags.
+func SelectTargetAccounts(ctx context.Context, org aws.OrganizationsClient, orgAccounts []aws.OrganizationAccount, selection AccountSelection) ([]string, error) {
+	slog.Debug("processing target accounts", "accounts_flag", selection.Accounts, "ou_flag", selection.OUs, "recursive", selection.Recursive,
+		"exclude", selection.Exclude, "name_match", selection.NameMatch, "include_status", selection.IncludeStatus)
+	accountsFlag := selection.Accounts
+	allFlag := len(accountsFlag) > 0 && accountsFlag[0] == AllAccountsOption
+	if allFlag && len(selection.OUs) > 0 {
+		return nil, fmt.Errorf("--accounts %s cannot be combined with --ou: use --ou alone to target the accounts of the organizational units", AllAccountsOption)
+	}
+
+	filter, err := newAccountFilter(selection)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkMemberAccounts(orgAccounts, selection); err != nil {
+		return nil, err
+	}
+
+	// if accounts or organizational units are provided and "all" is not specified, resolve them
+	if !allFlag && (len(accountsFlag) > 0 || len(selection.FromFile) > 0 || len(selection.OUs) > 0) {
+		accounts := slices.Clone(accountsFlag)
+		for _, account := range selection.FromFile {
+			if !slices.Contains(accounts, account) {
+				accounts = append(accounts, account)
+			}
+		}
+		if len(selection.OUs) > 0 {
+			ouAccounts, err := aws.GetOrganizationalUnitAccounts(ctx, org, selection.OUs, selection.Recursive)
+			if err != nil {
+				return nil, fmt.Errorf("error fetching organizational unit accounts: %w", err)
+			}
+			for _, account := range selection.targetableAccounts(ouAccounts) {
+				if !slices.Contains(accounts, account.AccountID) {
+					accounts = append(accounts, account.AccountID)
+				}
+			}
+		}
+		accounts, err = filter.applyIds(ctx, org, orgAccounts, accounts)
+		if err != nil {
+			return nil, err
+		}
+		return filterByTags(ctx, org, accounts, selection)
+	}
+
+	orgAccounts, err = filter.apply(ctx, org, selection.targetableAccounts(orgAccounts))
+	if err != nil {
+		return nil, err
+	}
+
+	// if "all" is specified or tags are filtered, return all (matching) account IDs
+	if allFlag || len(selection.Tags) > 0 {
+		return filterByTags(ctx, org, convertAccountsToIDs(orgAccounts), selection)
 	}
 
 	// prompt the user for account selection
 	var selectorChoices []string
 	selectorChoices = append(selectorChoices, AllAccountsSelectorText)
 	for _, account := range orgAccounts {
-		selectorChoices = append(selectorChoices, fmt.Sprintf("%s - %s", account.AccountID, account.Name))
+		choice := fmt.Sprintf("%s - %s", account.AccountID, account.Name)
+		if account.Status != aws.AccountStatusActive {
+			choice += fmt.Sprintf(" (%s)", account.Status)
+		}
+		selectorChoices = append(selectorChoices, choice)
 	}
 	selectedIndexes, err := Prompt("Please select the AWS accounts to audit", selectorChoices)
 	if err != nil {
@@ -58,10 +146,52 @@ func SelectTargetAccounts(ctx context.Context, org aws.OrganizationsClient, acco
 	return extractSelectedAccounts(orgAccounts, selectedIndexes), nil
 }
 
+// checkMemberAccounts returns an error listing the accounts read from a file that
+// are not in orgAccounts, or whose status is not targeted.
+func checkMemberAccounts(orgAccounts []aws.OrganizationAccount, selection AccountSelection) error {
+	if len(selection.FromFile) == 0 {
+		return nil
+	}
+	statuses := make(map[string]string, len(orgAccounts))
+	for _, account := range orgAccounts {
+		statuses[account.AccountID] = account.Status
+	}
+
+	var unknown, untargetable []string
+	for _, account := range selection.FromFile {
+		status, ok := statuses[account]
+		switch {
+		case !ok:
+			unknown = append(unknown, account)
+		case !selection.targetable(status):
+			untargetable = append(untargetable, fmt.Sprintf("%s (%s)", account, status))
+		}
+	}
+	var errs []error
+	if len(unknown) > 0 {
+		errs = append(errs, fmt.Errorf("%d account(s) are not organization member accounts: %s", len(unknown), strings.Join(unknown, ", ")))
+	}
+	if len(untargetable) > 0 {
+		errs = append(errs, fmt.Errorf("%d account(s) are not active: %s", len(untargetable), strings.Join(untargetable, ", ")))
+	}
+	return errors.Join(errs...)
+}
+
+// filterByTags keeps the accounts matching the tag filters of selection.
+func filterByTags(ctx context.Context, org aws.OrganizationsClient, accounts []string, selection AccountSelection) ([]string, error) {
+	if len(selection.Tags) == 0 {
+		return accounts, nil
+	}
+	filtered, err := aws.FilterAccountsByTags(ctx, org, accounts, selection.Tags, selection.MatchAnyTag, selection.Concurrency)
+	if err != nil {
+		return nil, fmt.Errorf("error filtering accounts by tags: %w", err)
+	}
+	return filtered, nil
+}
+
 // SelectSingleTargetAccount resolves exactly one account ID from the flag or
-// a single-select TUI. Unlike SelectTargetAccounts it has no "all" option.
-// org is only used when accountsFlag is empty.
-func SelectSingleTargetAccount(ctx context.Context, org aws.OrganizationsClient, accountsFlag []string) (string, error) {
+// a single-select TUI of orgAccounts. Unlike SelectTargetAccounts it has no "all" option.
+func SelectSingleTargetAccount(orgAccounts []aws.OrganizationAccount, accountsFlag []string) (string, error) {
 	slog.Debug("processing single target account", "accounts_flag", accountsFlag)
 
 	if len(accountsFlag) == 1 && accountsFlag[0] != AllAccountsOption {
@@ -71,10 +201,7 @@ func SelectSingleTargetAccount(ctx context.Context, org aws.OrganizationsClient,
 		return "", fmt.Errorf("this command operates on a single account; got %d", len(accountsFlag))
 	}
 
-	orgAccounts, err := aws.GetNonManagementOrganizationAccounts(ctx, org)
-	if err != nil {
-		return "", fmt.Errorf("error fetching organization accounts: %w", err)
-	}
+	orgAccounts = AccountSelection{}.targetableAccounts(orgAccounts)
 
 	var choices []string
 	for _, account := range orgAccounts {
@@ -15,6 +15,15 @@ type mockOrganizationsClient struct {
 	accounts          []aws.OrganizationAccount
 	describeErr       error
 	listErr           error
+
+	// accounts and child organizational units by parent ID
+	parentAccounts map[string][]aws.OrganizationAccount
+	childOUs       map[string][]string
+	listParentErr  error
+
+	// tags by account ID
+	tags       map[string]map[string]string
+	listTagErr error
 }
 
 func (m *mockOrganizationsClient) DescribeOrganization(_ context.Context) (string, error) {
@@ -25,13 +34,34 @@ func (m *mockOrganizationsClient) ListAccounts(_ context.Context) ([]aws.Organiz
 	return m.accounts, m.listErr
 }
 
+func (m *mockOrganizationsClient) ListAccountsForParent(_ context.Context, parentId string) ([]aws.OrganizationAccount, error) {
+	return m.parentAccounts[parentId], m.listParentErr
+}
+
+func (m *mockOrganizationsClient) ListChildOrganizationalUnits(_ context.Context, parentId string) ([]string, error) {
+	return m.childOUs[parentId], nil
+}
+
+func (m *mockOrganizationsClient) ListTagsForResource(_ context.Context, resourceId string) (map[string]string, error) {
+	return m.tags[resourceId], m.listTagErr
+}
+
 func (m *mockOrganizationsClient) EnableAWSServiceAccess(_ context.Context, _ string) error {
 	return nil
 }
 
+// selectTargets lists the accounts of org once, like the commands do, and
+// selects the target accounts.
+func selectTargets(t *testing.T, org *mockOrganizationsClient, selection AccountSelection) ([]string, error) {
+	t.Helper()
+	orgAccounts, err := ListOrganizationAccounts(context.Background(), org)
+	require.NoError(t, err)
+	return SelectTargetAccounts(context.Background(), org, orgAccounts, selection)
+}
+
 func TestSelectTargetAccounts_ExplicitIDs(t *testing.T) {
-	// org is nil to prove it's never called on the explicit IDs path
-	accounts, err := SelectTargetAccounts(context.Background(), nil, []string{"123456789012", "234567890123"})
+	// org and the organization accounts are nil to prove they are not needed on the explicit IDs path
+	accounts, err := SelectTargetAccounts(context.Background(), nil, nil, AccountSelection{Accounts: []string{"123456789012", "234567890123"}})
 	require.NoError(t, err)
 	assert.Equal(t, []string{"123456789012", "234567890123"}, accounts)
 }
@@ -40,35 +70,305 @@ func TestSelectTargetAccounts_AllFlag(t *testing.T) {
 	mock := &mockOrganizationsClient{
 		managementAccount: "000000000000",
 		accounts: []aws.OrganizationAccount{
-			{AccountID: "111111111111", Name: "account-a"},
-			{AccountID: "000000000000", Name: "management"},
-			{AccountID: "222222222222", Name: "account-b"},
+			{AccountID: "111111111111", Name: "account-a", Status: aws.AccountStatusActive},
+			{AccountID: "000000000000", Name: "management", Status: aws.AccountStatusActive},
+			{AccountID: "222222222222", Name: "account-b", Status: aws.AccountStatusActive},
 		},
 	}
 
-	accounts, err := SelectTargetAccounts(context.Background(), mock, []string{"all"})
+	accounts, err := selectTargets(t, mock, AccountSelection{Accounts: []string{"all"}})
 	require.NoError(t, err)
 	assert.Len(t, accounts, 2)
 	assert.NotContains(t, accounts, "000000000000")
 }
 
-func TestSelectTargetAccounts_DescribeOrgError(t *testing.T) {
+func TestListOrganizationAccounts_DescribeOrgError(t *testing.T) {
 	orgErr := errors.New("organizations API unavailable")
 	mock := &mockOrganizationsClient{describeErr: orgErr}
 
-	_, err := SelectTargetAccounts(context.Background(), mock, []string{"all"})
+	_, err := ListOrganizationAccounts(context.Background(), mock)
 	require.Error(t, err)
 	assert.ErrorIs(t, err, orgErr)
 }
 
-func TestSelectTargetAccounts_ListAccountsError(t *testing.T) {
+func TestListOrganizationAccounts_ListAccountsError(t *testing.T) {
 	listErr := errors.New("failed to list accounts")
 	mock := &mockOrganizationsClient{
 		managementAccount: "000000000000",
 		listErr:           listErr,
 	}
 
-	_, err := SelectTargetAccounts(context.Background(), mock, []string{"all"})
+	_, err := ListOrganizationAccounts(context.Background(), mock)
+	require.Error(t, err)
+	assert.ErrorIs(t, err, listErr)
+}
+
+func newOUMock() *mockOrganizationsClient {
+	return &mockOrganizationsClient{
+		managementAccount: "000000000000",
+		parentAccounts: map[string][]aws.OrganizationAccount{
+			"ou-abcd-sandbox1": {{AccountID: "111111111111", Status: aws.AccountStatusActive}, {AccountID: "000000000000", Status: aws.AccountStatusActive}},
+			"ou-abcd-nested01": {{AccountID: "222222222222", Status: aws.AccountStatusActive}},
+			"ou-abcd-deeper01": {{AccountID: "333333333333", Status: aws.AccountStatusActive}},
+		},
+		childOUs: map[string][]string{
+			"ou-abcd-sandbox1": {"ou-abcd-nested01"},
+			"ou-abcd-nested01": {"ou-abcd-deeper01"},
+		},
+	}
+}
+
+func TestSelectTargetAccounts_OU(t *testing.T) {
+	accounts, err := selectTargets(t, newOUMock(), AccountSelection{OUs: []string{"ou-abcd-sandbox1"}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"111111111111"}, accounts)
+}
+
+func TestSelectTargetAccounts_OURecursive(t *testing.T) {
+	accounts, err := selectTargets(t, newOUMock(), AccountSelection{OUs: []string{"ou-abcd-sandbox1"}, Recursive: true})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"111111111111", "222222222222", "333333333333"}, accounts)
+}
+
+func TestSelectTargetAccounts_OUWithExplicitAccounts(t *testing.T) {
+	selection := AccountSelection{Accounts: []string{"999999999999", "222222222222"}, OUs: []string{"ou-abcd-nested01", "ou-abcd-sandbox1"}}
+	accounts, err := selectTargets(t, newOUMock(), selection)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"999999999999", "222222222222", "111111111111"}, accounts)
+}
+
+func TestSelectTargetAccounts_OUWithAllAccounts(t *testing.T) {
+	_, err := selectTargets(t, newOUMock(), AccountSelection{Accounts: []string{AllAccountsOption}, OUs: []string{"ou-abcd-sandbox1"}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "--accounts all cannot be combined with --ou")
+}
+
+func TestSelectTargetAccounts_InvalidOU(t *testing.T) {
+	_, err := selectTargets(t, newOUMock(), AccountSelection{OUs: []string{"sandbox"}})
 	require.Error(t, err)
+	assert.Contains(t, err.Error(), `invalid organizational unit id "sandbox"`)
+}
+
+func TestSelectTargetAccounts_OUListError(t *testing.T) {
+	listErr := errors.New("parent not found")
+	mock := newOUMock()
+	mock.listParentErr = listErr
+
+	_, err := selectTargets(t, mock, AccountSelection{OUs: []string{"ou-abcd-sandbox1"}})
 	assert.ErrorIs(t, err, listErr)
 }
+
+func newTagMock() *mockOrganizationsClient {
+	mock := newOUMock()
+	mock.accounts = []aws.OrganizationAccount{
+		{AccountID: "111111111111", Status: aws.AccountStatusActive},
+		{AccountID: "000000000000", Status: aws.AccountStatusActive},
+		{AccountID: "222222222222", Status: aws.AccountStatusActive},
+		{AccountID: "333333333333", Status: aws.AccountStatusActive},
+	}
+	mock.tags = map[string]map[string]string{
+		"111111111111": {"env": "sandbox", "team": "platform"},
+		"222222222222": {"env": "sandbox"},
+		"333333333333": {"env": "prod", "team": "platform"},
+	}
+	return mock
+}
+
+func TestSelectTargetAccounts_TagsMatchAll(t *testing.T) {
+	selection := AccountSelection{Tags: []aws.TagFilter{{Key: "env", Value: "sandbox"}, {Key: "team", Value: "platform"}}}
+	accounts, err := selectTargets(t, newTagMock(), selection)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"111111111111"}, accounts)
+}
+
+func TestSelectTargetAccounts_TagsMatchAny(t *testing.T) {
+	selection := AccountSelection{Tags: []aws.TagFilter{{Key: "env", Value: "sandbox"}, {Key: "team", Value: "platform"}}, MatchAnyTag: true}
+	accounts, err := selectTargets(t, newTagMock(), selection)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"111111111111", "222222222222", "333333333333"}, accounts)
+}
+
+func TestSelectTargetAccounts_TagsWithOU(t *testing.T) {
+	selection := AccountSelection{
+		Accounts:  []string{"333333333333"},
+		OUs:       []string{"ou-abcd-sandbox1"},
+		Recursive: true,
+		Tags:      []aws.TagFilter{{Key: "team", Value: "platform"}},
+	}
+	accounts, err := selectTargets(t, newTagMock(), selection)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"333333333333", "111111111111"}, accounts)
+}
+
+func TestSelectTargetAccounts_TagListError(t *testing.T) {
+	tagErr := errors.New("access denied")
+	mock := newTagMock()
+	mock.listTagErr = tagErr
+
+	_, err := selectTargets(t, mock, AccountSelection{Tags: []aws.TagFilter{{Key: "env", Value: "sandbox"}}})
+	assert.ErrorIs(t, err, tagErr)
+}
+
+func newNamedMock() *mockOrganizationsClient {
+	mock := newOUMock()
+	mock.accounts = []aws.OrganizationAccount{
+		{AccountID: "111111111111", Name: "sandbox-a", Status: aws.AccountStatusActive},
+		{AccountID: "000000000000", Name: "management", Status: aws.AccountStatusActive},
+		{AccountID: "222222222222", Name: "log-archive", Status: aws.AccountStatusActive},
+		{AccountID: "333333333333", Name: "security-tooling", Status: aws.AccountStatusActive},
+		{AccountID: "444444444444", Name: "sandbox-b", Status: aws.AccountStatusActive},
+	}
+	return mock
+}
+
+func TestSelectTargetAccounts_Exclude(t *testing.T) {
+	tests := []struct {
+		name      string
+		selection AccountSelection
+		want      []string
+	}{
+		{
+			name:      "account IDs",
+			selection: AccountSelection{Accounts: []string{"all"}, Exclude: []string{"222222222222", "333333333333"}},
+			want:      []string{"111111111111", "444444444444"},
+		},
+		{
+			name:      "name pattern",
+			selection: AccountSelection{Accounts: []string{"all"}, Exclude: []string{"^(log-archive|security-tooling)$"}},
+			want:      []string{"111111111111", "444444444444"},
+		},
+		{
+			name:      "organizational unit including nested ones",
+			selection: AccountSelection{Accounts: []string{"all"}, Exclude: []string{"ou-abcd-nested01"}},
+			want:      []string{"111111111111", "444444444444"},
+		},
+		{
+			name:      "from organizational unit selection",
+			selection: AccountSelection{OUs: []string{"ou-abcd-sandbox1"}, Recursive: true, Exclude: []string{"security"}},
+			want:      []string{"111111111111", "222222222222"},
+		},
+		{
+			name:      "from explicit accounts",
+			selection: AccountSelection{Accounts: []string{"111111111111", "222222222222"}, Exclude: []string{"222222222222"}},
+			want:      []string{"111111111111"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			accounts, err := selectTargets(t, newNamedMock(), tt.selection)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, accounts)
+		})
+	}
+}
+
+func TestSelectTargetAccounts_ExcludeNameLikeOrganizationalUnit(t *testing.T) {
+	mock := newNamedMock()
+	mock.accounts[1].Name = "r-and-d"
+	mock.accounts[4].Name = "ou-legacy"
+
+	// names starting like organizational unit or root IDs are matched as names
+	accounts, err := selectTargets(t, mock, AccountSelection{Accounts: []string{"all"}, Exclude: []string{"r-and-d", "ou-legacy"}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"111111111111", "222222222222", "333333333333"}, accounts)
+}
+
+func TestSelectTargetAccounts_NameMatch(t *testing.T) {
+	selection := AccountSelection{Accounts: []string{"all"}, NameMatch: "^sandbox-", Exclude: []string{"444444444444"}}
+	accounts, err := selectTargets(t, newNamedMock(), selection)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"111111111111"}, accounts)
+}
+
+func TestSelectTargetAccounts_NameMatchExplicitAccounts(t *testing.T) {
+	// accounts outside the organization have no name to match
+	selection := AccountSelection{Accounts: []string{"999999999999", "444444444444", "222222222222"}, NameMatch: "sandbox"}
+	accounts, err := selectTargets(t, newNamedMock(), selection)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"444444444444"}, accounts)
+}
+
+func TestSelectTargetAccounts_InvalidPattern(t *testing.T) {
+	// org is nil to prove patterns are validated before any API call
+	_, err := SelectTargetAccounts(context.Background(), nil, nil, AccountSelection{Accounts: []string{"all"}, Exclude: []string{"log-(archive"}})
+	assert.ErrorContains(t, err, `invalid exclude pattern "log-(archive"`)
+
+	_, err = SelectTargetAccounts(context.Background(), nil, nil, AccountSelection{Accounts: []string{"all"}, NameMatch: "["})
+	assert.ErrorContains(t, err, `invalid name pattern "["`)
+}
+
+func TestSelectTargetAccounts_FromFile(t *testing.T) {
+	selection := AccountSelection{Accounts: []string{"444444444444"}, FromFile: []string{"111111111111", "444444444444", "222222222222"}}
+	accounts, err := selectTargets(t, newNamedMock(), selection)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"444444444444", "111111111111", "222222222222"}, accounts)
+}
+
+func TestSelectTargetAccounts_FromFileNotMembers(t *testing.T) {
+	selection := AccountSelection{FromFile: []string{"111111111111", "999999999999", "000000000000"}}
+	_, err := selectTargets(t, newNamedMock(), selection)
+	assert.EqualError(t, err, "2 account(s) are not organization member accounts: 999999999999, 000000000000")
+}
+
+func newStatusMock() *mockOrganizationsClient {
+	return &mockOrganizationsClient{
+		managementAccount: "000000000000",
+		accounts: []aws.OrganizationAccount{
+			{AccountID: "111111111111", Status: aws.AccountStatusActive},
+			{AccountID: "222222222222", Status: aws.AccountStatusSuspended},
+			{AccountID: "333333333333", Status: aws.AccountStatusPendingClosure},
+		},
+		parentAccounts: map[string][]aws.OrganizationAccount{
+			"ou-abcd-sandbox1": {{AccountID: "111111111111", Status: aws.AccountStatusActive}, {AccountID: "222222222222", Status: aws.AccountStatusSuspended}},
+		},
+	}
+}
+
+func TestSelectTargetAccounts_IncludeStatus(t *testing.T) {
+	tests := []struct {
+		name      string
+		selection AccountSelection
+		want      []string
+	}{
+		{
+			name:      "active only by default",
+			selection: AccountSelection{Accounts: []string{"all"}},
+			want:      []string{"111111111111"},
+		},
+		{
+			name:      "suspended included",
+			selection: AccountSelection{Accounts: []string{"all"}, IncludeStatus: []string{aws.AccountStatusSuspended}},
+			want:      []string{"111111111111", "222222222222"},
+		},
+		{
+			name:      "organizational unit",
+			selection: AccountSelection{OUs: []string{"ou-abcd-sandbox1"}},
+			want:      []string{"111111111111"},
+		},
+		{
+			name:      "organizational unit with suspended included",
+			selection: AccountSelection{OUs: []string{"ou-abcd-sandbox1"}, IncludeStatus: []string{aws.AccountStatusSuspended}},
+			want:      []string{"111111111111", "222222222222"},
+		},
+		{
+			name:      "accounts file with pending closure included",
+			selection: AccountSelection{FromFile: []string{"333333333333"}, IncludeStatus: []string{aws.AccountStatusPendingClosure}},
+			want:      []string{"333333333333"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			accounts, err := selectTargets(t, newStatusMock(), tt.selection)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, accounts)
+		})
+	}
+}
+
+func TestSelectTargetAccounts_FromFileInactive(t *testing.T) {
+	selection := AccountSelection{FromFile: []string{"111111111111", "222222222222", "333333333333"}}
+	_, err := selectTargets(t, newStatusMock(), selection)
+	assert.EqualError(t, err, "2 account(s) are not active: 222222222222 (SUSPENDED), 333333333333 (PENDING_CLOSURE)")
+}
@@ -0,0 +1,178 @@
+package ui
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	"github.com/charmbracelet/x/term"
+)
+
+const progressBarWidth = 30
+
+// progressLogInterval is how often progress is logged when stderr is not a terminal.
+var progressLogInterval = 10 * time.Second
+
+// progressLogOutput receives the progress lines logged when stderr is not a terminal.
+// They are written directly rather than through slog, so that they are shown
+// whatever the log level.
+var progressLogOutput io.Writer = os.Stderr
+
+var (
+	progressFilledStyle = lipgloss.NewStyle().Foreground(pink)
+	progressEmptyStyle  = lipgloss.NewStyle().Foreground(gray)
+	progressFailedStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("1"))
+)
+
+// progressState is a snapshot of the progress of an operation.
+type progressState struct {
+	title    string
+	done     int
+	failed   int
+	inFlight int
+	total    int
+}
+
+// Progress displays the live progress of a multi-account operation on stderr.
+// When stderr is a terminal it renders a progress view, otherwise it writes
+// periodic log lines.
+type Progress struct {
+	mu      sync.Mutex
+	state   progressState
+	program *tea.Program
+	stop    chan struct{}
+	stopped chan struct{}
+}
+
+// StartProgress starts displaying progress until Stop is called.
+func StartProgress() *Progress {
+	p := &Progress{
+		stop:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+
+	if term.IsTerminal(os.Stderr.Fd()) {
+		// input is disabled so the view neither grabs the keyboard nor swallows Ctrl-C
+		p.program = tea.NewProgram(progressModel{},
+			tea.WithOutput(os.Stderr),
+			tea.WithInput(nil),
+			tea.WithoutSignalHandler(),
+		)
+		go func() {
+			defer close(p.stopped)
+			if _, err := p.program.Run(); err != nil {
+				slog.Debug("progress view stopped", "error", err)
+			}
+		}()
+		return p
+	}
+
+	go p.logPeriodically()
+	return p
+}
+
+// Update sets the current progress of the operation described by title.
+func (p *Progress) Update(title string, done, failed, inFlight, total int) {
+	state := progressState{title: title, done: done, failed: failed, inFlight: inFlight, total: total}
+
+	p.mu.Lock()
+	p.state = state
+	p.mu.Unlock()
+
+	if p.program != nil {
+		p.program.Send(state)
+	}
+}
+
+// Stop stops displaying progress and waits until the display is finished.
+func (p *Progress) Stop() {
+	if p.program != nil {
+		p.program.Quit()
+	} else {
+		close(p.stop)
+	}
+	<-p.stopped
+}
+
+// logPeriodically logs the progress every progressLogInterval when it changed,
+// and a final line once stopped.
+func (p *Progress) logPeriodically() {
+	defer close(p.stopped)
+
+	ticker := time.NewTicker(progressLogInterval)
+	defer ticker.Stop()
+
+	var logged progressState
+	for {
+		select {
+		case <-ticker.C:
+			if state := p.snapshot(); state != logged && state.total > 0 {
+				logProgress(state)
+				logged = state
+			}
+		case <-p.stop:
+			if state := p.snapshot(); state != logged && state.total > 0 {
+				logProgress(state)
+			}
+			return
+		}
+	}
+}
+
+func (p *Progress) snapshot() progressState {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.state
+}
+
+func logProgress(state progressState) {
+	fmt.Fprintf(progressLogOutput, "%s: %d/%d done, %d failed, %d in flight\n",
+		state.title, state.done, state.total, state.failed, state.inFlight)
+}
+
+// progressModel is the bubbletea model of the progress view.
+type progressModel struct {
+	state progressState
+}
+
+func (m progressModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m progressModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if state, ok := msg.(progressState); ok {
+		m.state = state
+	}
+	return m, nil
+}
+
+func (m progressModel) View() tea.View {
+	if m.state.total == 0 {
+		return tea.NewView("")
+	}
+
+	filled := m.state.done * progressBarWidth / m.state.total
+	bar := progressFilledStyle.Render(strings.Repeat("█", filled)) +
+		progressEmptyStyle.Render(strings.Repeat("░", progressBarWidth-filled))
+
+	failed := fmt.Sprintf("%d failed", m.state.failed)
+	if m.state.failed > 0 {
+		failed = progressFailedStyle.Render(failed)
+	}
+
+	s := fmt.Sprintf("%s %s %d/%d • %s • %d in flight\n",
+		m.state.title,
+		bar,
+		m.state.done,
+		m.state.total,
+		failed,
+		m.state.inFlight,
+	)
+	return tea.NewView(s)
+}
@@ -0,0 +1,47 @@
+package ui
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/unicrons/aws-root-manager/internal/logger"
+)
+
+func TestProgressModel_View(t *testing.T) {
+	m := progressModel{}
+	assert.Empty(t, m.View().Content)
+
+	updated, _ := m.Update(progressState{title: "Auditing accounts", done: 3, failed: 1, inFlight: 2, total: 10})
+	view := updated.(progressModel).View().Content
+
+	assert.Contains(t, view, "Auditing accounts")
+	assert.Contains(t, view, "3/10")
+	assert.Contains(t, view, "1 failed")
+	assert.Contains(t, view, "2 in flight")
+}
+
+func TestProgress_LogsWhenNotATerminal(t *testing.T) {
+	// the default logger configuration only prints errors, progress is shown anyway
+	previousLogger := slog.Default()
+	logger.Configure("", "text")
+	t.Cleanup(func() { slog.SetDefault(previousLogger) })
+
+	var buf bytes.Buffer
+	previousOutput := progressLogOutput
+	progressLogOutput = &buf
+	t.Cleanup(func() { progressLogOutput = previousOutput })
+
+	previousInterval := progressLogInterval
+	progressLogInterval = time.Hour
+	t.Cleanup(func() { progressLogInterval = previousInterval })
+
+	// stderr is not a terminal under go test, so progress is logged
+	progress := StartProgress()
+	progress.Update("Deleting credentials", 4, 1, 0, 4)
+	progress.Stop()
+
+	assert.Equal(t, "Deleting credentials: 4/4 done, 1 failed, 0 in flight\n", buf.String())
+}
@@ -30,10 +30,17 @@ type RootManager interface {
 
 	// DeleteCredentials deletes root credentials for the specified accounts.
 	// The creds parameter should contain audit results identifying what credentials exist.
-	// The credentialType parameter specifies what to delete: "all", "login", "keys", "mfa", or "certificate".
-	// Returns a slice of DeletionResult showing the outcome for each account.
+	// The credentialType parameter specifies what to delete: "all", "login", "keys", "mfa", or "certificate",
+	// or a comma-separated list of types such as "login,keys,certificate" to delete several in one pass.
+	// Returns a slice of DeletionResult showing the outcome for each account and each credential.
+	// A failed credential does not stop the deletion of the other credentials of the account.
 	DeleteCredentials(ctx context.Context, creds []RootCredentials, credentialType string) ([]DeletionResult, error)
 
+	// DeleteCredentialItems deletes specific root credentials, such as a single access key,
+	// in each requested account. Credentials of an account not listed in its request are kept.
+	// Returns a slice of DeletionResult showing the outcome for each request.
+	DeleteCredentialItems(ctx context.Context, requests []DeletionRequest) ([]DeletionResult, error)
+
 	// RecoverRootPassword initiates root password recovery for the specified accounts.
 	// This triggers AWS to send password reset emails to the account's root email address.
 	// Returns a slice of RecoveryResult showing the outcome for each account.
@@ -2,41 +2,46 @@ package rootmanager
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
-	"sync"
 
 	"github.com/unicrons/aws-root-manager/internal/aws"
 )
 
 // auditAccounts returns root credentials for a list of AWS accounts.
-func auditAccounts(ctx context.Context, iam aws.IamClient, sts aws.StsClient, factory aws.IamClientFactory, accounts []string) ([]RootCredentials, error) {
+// At most opts.concurrency accounts are audited in parallel. If ctx is done
+// before every account was started, the remaining ones are marked as cancelled.
+func auditAccounts(ctx context.Context, iam aws.IamClient, sts aws.StsClient, factory aws.IamClientFactory, accounts []string, opts options) ([]RootCredentials, error) {
 	slog.Debug("auditing accounts", "accounts", accounts)
 
 	rootCredentials := make([]RootCredentials, len(accounts))
-	var wgAccounts sync.WaitGroup
 
-	if err := iam.CheckOrganizationRootAccess(ctx, false); err != nil {
+	// an access check failing because ctx is done is not an error: no account is
+	// started and all of them are reported as cancelled
+	if err := iam.CheckOrganizationRootAccess(ctx, false); err != nil && ctx.Err() == nil {
 		return nil, err
 	}
 
-	for i, accountId := range accounts {
-		wgAccounts.Add(1)
-		go func(idx int, accountId string) {
-			defer wgAccounts.Done()
-			if accStatus, err := auditAccount(ctx, sts, factory, accountId); err != nil {
-				rootCredentials[idx] = RootCredentials{AccountId: accountId, Error: err.Error()}
-			} else {
-				rootCredentials[idx] = accStatus
-			}
-		}(i, accountId)
+	processed := forEachAccount(ctx, opts, OperationAudit, accounts, func(ctx context.Context, idx int) bool {
+		accountId := accounts[idx]
+		accStatus, err := auditAccount(ctx, sts, factory, accountId)
+		if err != nil {
+			rootCredentials[idx] = RootCredentials{AccountId: accountId, Error: err.Error()}
+			return true
+		}
+		rootCredentials[idx] = accStatus
+		return accStatus.Partial()
+	})
+	for idx := processed; idx < len(accounts); idx++ {
+		rootCredentials[idx] = RootCredentials{AccountId: accounts[idx], Cancelled: true, Error: cancelledError(ctx)}
 	}
 
-	wgAccounts.Wait()
-
 	return rootCredentials, nil
 }
 
 // auditAccount returns root credentials for a specific account.
+// A failing check is recorded in RootCredentials.Checks without discarding the
+// results of the other checks; only an AssumeRoot failure fails the whole account.
 func auditAccount(ctx context.Context, sts aws.StsClient, factory aws.IamClientFactory, accountId string) (RootCredentials, error) {
 	slog.Debug("auditing account", "account_id", accountId)
 
@@ -46,39 +51,114 @@ func auditAccount(ctx context.Context, sts aws.StsClient, factory aws.IamClientF
 	}
 
 	iamRoot := factory.NewIamClient(awscfgRoot)
-	var accountRootCredentials RootCredentials
+	accountRootCredentials := RootCredentials{AccountId: accountId}
+
+	accountRootCredentials.addCheck(CheckLogin, auditLoginProfile(ctx, iamRoot, &accountRootCredentials))
+	accountRootCredentials.addCheck(CheckKeys, auditAccessKeys(ctx, iamRoot, &accountRootCredentials))
+	accountRootCredentials.addCheck(CheckMFA, auditMFADevices(ctx, iamRoot, &accountRootCredentials))
+	accountRootCredentials.addCheck(CheckCertificates, auditSigningCertificates(ctx, iamRoot, &accountRootCredentials))
+
+	return accountRootCredentials, nil
+}
 
-	loginProfile, err := iamRoot.GetLoginProfile(ctx, accountId)
+// auditLoginProfile fills the login profile and password usage of creds.
+func auditLoginProfile(ctx context.Context, iamRoot aws.IamClient, creds *RootCredentials) error {
+	loginProfile, err := iamRoot.GetLoginProfile(ctx, creds.AccountId)
 	if err != nil {
-		return accountRootCredentials, err
+		return err
 	}
-	slog.Debug("audit result", "account_id", accountId, "login_profile", loginProfile)
+	slog.Debug("audit result", "account_id", creds.AccountId, "login_profile", loginProfile.Exists)
+
+	creds.LoginProfile = loginProfile.Exists
+	creds.LoginProfileCreateDate = loginProfile.CreateDate
 
-	accessKeys, err := iamRoot.ListAccessKeys(ctx, accountId)
+	// the login profile is still reported without the password usage, so that it can be deleted
+	passwordLastUsed, err := iamRoot.GetPasswordLastUsed(ctx, creds.AccountId)
 	if err != nil {
-		return accountRootCredentials, err
+		creds.PasswordLastUsedError = err.Error()
+		creds.Warnings = append(creds.Warnings, fmt.Sprintf("password last used unknown: %v", err))
+		return nil
 	}
-	slog.Debug("audit result", "account_id", accountId, "access_keys", accessKeys)
+	slog.Debug("audit result", "account_id", creds.AccountId, "password_last_used", passwordLastUsed)
+
+	creds.PasswordLastUsed = passwordLastUsed
+	return nil
+}
 
-	mfaDevices, err := iamRoot.ListMFADevices(ctx, accountId)
+// auditAccessKeys fills the access keys of creds.
+func auditAccessKeys(ctx context.Context, iamRoot aws.IamClient, creds *RootCredentials) error {
+	accessKeys, err := iamRoot.ListAccessKeys(ctx, creds.AccountId)
 	if err != nil {
-		return accountRootCredentials, err
+		return err
 	}
-	slog.Debug("audit result", "account_id", accountId, "mfa_devices", mfaDevices)
+	slog.Debug("audit result", "account_id", creds.AccountId, "access_keys", accessKeys)
 
-	certificates, err := iamRoot.ListSigningCertificates(ctx, accountId)
+	creds.AccessKeys = convertAccessKeys(accessKeys)
+	for _, key := range accessKeys {
+		if key.LastUsedError != "" {
+			creds.Warnings = append(creds.Warnings, key.LastUsedError)
+		}
+	}
+	return nil
+}
+
+// auditMFADevices fills the MFA devices of creds.
+func auditMFADevices(ctx context.Context, iamRoot aws.IamClient, creds *RootCredentials) error {
+	mfaDevices, err := iamRoot.ListMFADevices(ctx, creds.AccountId)
 	if err != nil {
-		return accountRootCredentials, err
+		return err
 	}
-	slog.Debug("audit result", "account_id", accountId, "signing_certificates", certificates)
-
-	accountRootCredentials = RootCredentials{
-		AccountId:           accountId,
-		LoginProfile:        loginProfile,
-		AccessKeys:          accessKeys,
-		MfaDevices:          mfaDevices,
-		SigningCertificates: certificates,
+	slog.Debug("audit result", "account_id", creds.AccountId, "mfa_devices", mfaDevices)
+
+	creds.MfaDevices = convertMFADevices(mfaDevices)
+	return nil
+}
+
+// auditSigningCertificates fills the signing certificates of creds.
+func auditSigningCertificates(ctx context.Context, iamRoot aws.IamClient, creds *RootCredentials) error {
+	certificates, err := iamRoot.ListSigningCertificates(ctx, creds.AccountId)
+	if err != nil {
+		return err
 	}
+	slog.Debug("audit result", "account_id", creds.AccountId, "signing_certificates", certificates)
 
-	return accountRootCredentials, nil
+	creds.SigningCertificates = convertSigningCertificates(certificates)
+	return nil
+}
+
+// addCheck records the outcome of an audit check.
+func (c *RootCredentials) addCheck(check string, err error) {
+	result := CheckResult{Check: check, Success: err == nil}
+	if err != nil {
+		slog.Debug("audit check failed", "account_id", c.AccountId, "check", check, "error", err)
+		result.Error = err.Error()
+	}
+	c.Checks = append(c.Checks, result)
+}
+
+// convertAccessKeys converts access keys returned by the IAM client to the public AccessKey type.
+func convertAccessKeys(keys []aws.AccessKey) []AccessKey {
+	var accessKeys []AccessKey
+	for _, key := range keys {
+		accessKeys = append(accessKeys, AccessKey(key))
+	}
+	return accessKeys
+}
+
+// convertMFADevices converts MFA devices returned by the IAM client to the public MFADevice type.
+func convertMFADevices(devices []aws.MFADevice) []MFADevice {
+	var mfaDevices []MFADevice
+	for _, device := range devices {
+		mfaDevices = append(mfaDevices, MFADevice(device))
+	}
+	return mfaDevices
+}
+
+// convertSigningCertificates converts signing certificates returned by the IAM client to the public SigningCertificate type.
+func convertSigningCertificates(certificates []aws.SigningCertificate) []SigningCertificate {
+	var signingCertificates []SigningCertificate
+	for _, certificate := range certificates {
+		signingCertificates = append(signingCertificates, SigningCertificate(certificate))
+	}
+	return signingCertificates
 }
@@ -4,9 +4,11 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/unicrons/aws-root-manager/internal/aws"
 )
 
 func TestAuditAccounts_CheckAccessError(t *testing.T) {
@@ -15,28 +17,43 @@ func TestAuditAccounts_CheckAccessError(t *testing.T) {
 		checkOrgRootAccessErrs: []error{accessErr},
 	}
 
-	_, err := auditAccounts(context.Background(), iam, nil, nil, []string{"123456789012"})
+	_, err := auditAccounts(context.Background(), iam, nil, nil, []string{"123456789012"}, newOptions())
 	require.Error(t, err)
 	assert.ErrorIs(t, err, accessErr)
 }
 
 func TestAuditAccounts_Success(t *testing.T) {
+	created := time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC)
+	lastUsed := time.Date(2024, 5, 6, 0, 0, 0, 0, time.UTC)
 	rootIam := &mockIamClient{
-		getLoginProfileResult: true,
-		listAccessKeysResult:  []string{"AKIA123"},
-		listMFADevicesResult:  []string{},
-		listCertsResult:       []string{},
+		getLoginProfileResult:     aws.LoginProfile{Exists: true, CreateDate: created},
+		getPasswordLastUsedResult: lastUsed,
+		listAccessKeysResult:      []aws.AccessKey{{AccessKeyId: "AKIA123", Status: "Active", CreateDate: created, LastUsedDate: lastUsed, LastUsedService: "s3", LastUsedRegion: "us-east-1"}},
+		listMFADevicesResult:      []aws.MFADevice{{SerialNumber: "GAHT12345678", Type: aws.MFADeviceTypeHardware, EnableDate: created}},
+		listCertsResult:           []aws.SigningCertificate{{CertificateId: "CERT123", Status: "Inactive", UploadDate: created}},
 	}
 	sts := &mockStsClient{}
 	factory := &mockIamClientFactory{client: rootIam}
 	iam := &mockIamClient{}
 
-	results, err := auditAccounts(context.Background(), iam, sts, factory, []string{"123456789012"})
+	results, err := auditAccounts(context.Background(), iam, sts, factory, []string{"123456789012"}, newOptions())
 	require.NoError(t, err)
 	require.Len(t, results, 1)
 	assert.Empty(t, results[0].Error)
+	assert.False(t, results[0].Partial())
 	assert.True(t, results[0].LoginProfile)
-	assert.Equal(t, []string{"AKIA123"}, results[0].AccessKeys)
+	assert.Equal(t, created, results[0].LoginProfileCreateDate)
+	assert.Equal(t, lastUsed, results[0].PasswordLastUsed)
+	assert.Equal(t, []AccessKey{{
+		AccessKeyId:     "AKIA123",
+		Status:          "Active",
+		CreateDate:      created,
+		LastUsedDate:    lastUsed,
+		LastUsedService: "s3",
+		LastUsedRegion:  "us-east-1",
+	}}, results[0].AccessKeys)
+	assert.Equal(t, []SigningCertificate{{CertificateId: "CERT123", Status: "Inactive", UploadDate: created}}, results[0].SigningCertificates)
+	assert.Equal(t, []MFADevice{{SerialNumber: "GAHT12345678", Type: MFADeviceTypeHardware, EnableDate: created}}, results[0].MfaDevices)
 }
 
 func TestAuditAccounts_STSError(t *testing.T) {
@@ -44,7 +61,7 @@ func TestAuditAccounts_STSError(t *testing.T) {
 	sts := &mockStsClient{assumeRootErr: stsErr}
 	iam := &mockIamClient{}
 
-	results, err := auditAccounts(context.Background(), iam, sts, nil, []string{"123456789012"})
+	results, err := auditAccounts(context.Background(), iam, sts, nil, []string{"123456789012"}, newOptions())
 	require.NoError(t, err)
 	require.Len(t, results, 1)
 	assert.NotEmpty(t, results[0].Error)
@@ -57,7 +74,82 @@ func TestAuditAccounts_MultipleAccounts(t *testing.T) {
 	iam := &mockIamClient{}
 
 	accounts := []string{"111111111111", "222222222222", "333333333333"}
-	results, err := auditAccounts(context.Background(), iam, sts, factory, accounts)
+	results, err := auditAccounts(context.Background(), iam, sts, factory, accounts, newOptions())
 	require.NoError(t, err)
 	assert.Len(t, results, len(accounts))
 }
+
+func TestAuditAccounts_PasswordLastUsedError(t *testing.T) {
+	rootIam := &mockIamClient{
+		getLoginProfileResult:  aws.LoginProfile{Exists: true},
+		getPasswordLastUsedErr: errors.New("access denied"),
+	}
+	sts := &mockStsClient{}
+	factory := &mockIamClientFactory{client: rootIam}
+	iam := &mockIamClient{}
+
+	results, err := auditAccounts(context.Background(), iam, sts, factory, []string{"123456789012"}, newOptions())
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.True(t, results[0].LoginProfile)
+	assert.True(t, results[0].PasswordLastUsed.IsZero())
+	assert.Equal(t, "access denied", results[0].PasswordLastUsedError)
+	assert.Empty(t, results[0].CheckError(CheckLogin))
+	assert.Equal(t, []string{"password last used unknown: access denied"}, results[0].Warnings)
+	assert.NoError(t, checkCredentialsAudited(results[0], CheckLogin))
+}
+
+func TestAuditAccounts_AccessKeyLastUsedError(t *testing.T) {
+	rootIam := &mockIamClient{
+		listAccessKeysResult: []aws.AccessKey{{AccessKeyId: "AKIA1", LastUsedError: "error getting last usage of access key AKIA1"}},
+	}
+	sts := &mockStsClient{}
+	factory := &mockIamClientFactory{client: rootIam}
+	iam := &mockIamClient{}
+
+	results, err := auditAccounts(context.Background(), iam, sts, factory, []string{"123456789012"}, newOptions())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"AKIA1"}, accessKeyIds(results[0].AccessKeys))
+	assert.Empty(t, results[0].CheckError(CheckKeys))
+	assert.Equal(t, []string{"error getting last usage of access key AKIA1"}, results[0].Warnings)
+}
+
+func TestAuditAccounts_PartialResults(t *testing.T) {
+	rootIam := &mockIamClient{
+		getLoginProfileResult: aws.LoginProfile{Exists: true},
+		listAccessKeysResult:  []aws.AccessKey{{AccessKeyId: "AKIA123"}},
+		listCertsErr:          errors.New("throttled"),
+	}
+	sts := &mockStsClient{}
+	factory := &mockIamClientFactory{client: rootIam}
+	iam := &mockIamClient{}
+
+	results, err := auditAccounts(context.Background(), iam, sts, factory, []string{"123456789012"}, newOptions())
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Empty(t, results[0].Error)
+	assert.True(t, results[0].Partial())
+	assert.True(t, results[0].LoginProfile)
+	assert.Equal(t, []AccessKey{{AccessKeyId: "AKIA123"}}, results[0].AccessKeys)
+	assert.Equal(t, []CheckResult{
+		{Check: CheckLogin, Success: true},
+		{Check: CheckKeys, Success: true},
+		{Check: CheckMFA, Success: true},
+		{Check: CheckCertificates, Success: false, Error: "throttled"},
+	}, results[0].Checks)
+}
+
+func TestAuditAccounts_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	iam := &mockIamClient{checkOrgRootAccessErrs: []error{context.Canceled}}
+
+	results, err := auditAccounts(ctx, iam, &mockStsClient{}, nil, []string{"111111111111", "222222222222"}, newOptions())
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	for _, result := range results {
+		assert.True(t, result.Cancelled)
+		assert.Contains(t, result.Error, "context canceled")
+	}
+	assert.Equal(t, "222222222222", results[1].AccountId)
+}
@@ -0,0 +1,111 @@
+package rootmanager
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+)
+
+// BreakGlassAccount is an account whose root user is kept for emergency access
+// and must therefore have a login profile protected by a hardware MFA device.
+type BreakGlassAccount struct {
+	AccountId   string // AWS account ID
+	Description string // Why the account is used for break-glass access
+}
+
+// breakGlassFile is the json format of a break-glass registry file.
+type breakGlassFile struct {
+	Accounts []struct {
+		AccountId   string `json:"account_id"`
+		Description string `json:"description"`
+	} `json:"accounts"`
+}
+
+// LoadBreakGlassRegistry reads and validates the break-glass registry file at path.
+func LoadBreakGlassRegistry(path string) ([]BreakGlassAccount, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read break-glass registry: %w", err)
+	}
+	return ParseBreakGlassRegistry(data)
+}
+
+// ParseBreakGlassRegistry parses and validates a break-glass registry in json format:
+//
+//	{"accounts": [{"account_id": "123456789012", "description": "security break-glass"}]}
+func ParseBreakGlassRegistry(data []byte) ([]BreakGlassAccount, error) {
+	var file breakGlassFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to decode break-glass registry: %w", err)
+	}
+
+	accounts := make([]BreakGlassAccount, len(file.Accounts))
+	var errs []error
+	for i, entry := range file.Accounts {
+		if !ValidAccountId(entry.AccountId) {
+			errs = append(errs, fmt.Errorf("break-glass account %d: invalid account_id %q", i+1, entry.AccountId))
+		}
+		if slices.ContainsFunc(accounts[:i], func(account BreakGlassAccount) bool { return account.AccountId == entry.AccountId }) {
+			errs = append(errs, fmt.Errorf("break-glass account %d: duplicate account_id %q", i+1, entry.AccountId))
+		}
+		accounts[i] = BreakGlassAccount{AccountId: entry.AccountId, Description: entry.Description}
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return accounts, nil
+}
+
+// BreakGlassResult is the compliance of a break-glass account.
+type BreakGlassResult struct {
+	AccountId string   // AWS account ID
+	Compliant bool     // Whether the account has a login profile and a hardware MFA device
+	Issues    []string // Why the account is not compliant (empty if Compliant=true)
+}
+
+// CheckBreakGlass verifies that a break-glass account has a root login profile
+// and at least one hardware or FIDO MFA device. Credentials whose audit check
+// failed cannot be verified and make the account non-compliant.
+func CheckBreakGlass(creds RootCredentials) BreakGlassResult {
+	var issues []string
+
+	switch checkErr := creds.CheckError(CheckLogin); {
+	case creds.Error != "":
+		issues = append(issues, "audit failed: "+creds.Error)
+	case checkErr != "":
+		issues = append(issues, "login profile not verified: "+checkErr)
+	case !creds.LoginProfile:
+		issues = append(issues, "login profile missing")
+	}
+
+	if creds.Error == "" {
+		if checkErr := creds.CheckError(CheckMFA); checkErr != "" {
+			issues = append(issues, "MFA not verified: "+checkErr)
+		} else if issue := breakGlassMFAIssue(creds.MfaDevices); issue != "" {
+			issues = append(issues, issue)
+		}
+	}
+
+	return BreakGlassResult{AccountId: creds.AccountId, Compliant: len(issues) == 0, Issues: issues}
+}
+
+// breakGlassMFAIssue returns why the MFA devices do not protect a break-glass
+// account, or an empty string if one of them is a hardware or FIDO device.
+func breakGlassMFAIssue(devices []MFADevice) string {
+	if len(devices) == 0 {
+		return "MFA device missing"
+	}
+	var types []string
+	for _, device := range devices {
+		if device.Type == MFADeviceTypeHardware || device.Type == MFADeviceTypeFIDO {
+			return ""
+		}
+		if !slices.Contains(types, device.Type) {
+			types = append(types, device.Type)
+		}
+	}
+	return fmt.Sprintf("no hardware MFA device (found %s)", strings.Join(types, ", "))
+}
@@ -0,0 +1,65 @@
+package rootmanager
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseBreakGlassRegistry(t *testing.T) {
+	accounts, err := ParseBreakGlassRegistry([]byte(`{"accounts": [{"account_id": "123456789012", "description": "security"}]}`))
+	require.NoError(t, err)
+	assert.Equal(t, []BreakGlassAccount{{AccountId: "123456789012", Description: "security"}}, accounts)
+}
+
+func TestParseBreakGlassRegistry_Invalid(t *testing.T) {
+	_, err := ParseBreakGlassRegistry([]byte(`{"accounts": [{"account_id": "123"}, {"account_id": "123456789012"}, {"account_id": "123456789012"}]}`))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `invalid account_id "123"`)
+	assert.Contains(t, err.Error(), `break-glass account 3: duplicate account_id "123456789012"`)
+}
+
+func TestCheckBreakGlass(t *testing.T) {
+	tests := []struct {
+		name   string
+		creds  RootCredentials
+		issues []string
+	}{
+		{
+			name:  "hardware MFA",
+			creds: RootCredentials{LoginProfile: true, MfaDevices: []MFADevice{{Type: MFADeviceTypeVirtual}, {Type: MFADeviceTypeHardware}}},
+		},
+		{
+			name:  "FIDO MFA",
+			creds: RootCredentials{LoginProfile: true, MfaDevices: []MFADevice{{Type: MFADeviceTypeFIDO}}},
+		},
+		{
+			name:   "missing login profile and MFA",
+			creds:  RootCredentials{},
+			issues: []string{"login profile missing", "MFA device missing"},
+		},
+		{
+			name:   "virtual MFA only",
+			creds:  RootCredentials{LoginProfile: true, MfaDevices: []MFADevice{{Type: MFADeviceTypeVirtual}}},
+			issues: []string{"no hardware MFA device (found virtual)"},
+		},
+		{
+			name:   "failed checks",
+			creds:  RootCredentials{Checks: []CheckResult{{Check: CheckLogin, Error: "denied"}, {Check: CheckMFA, Error: "throttled"}}},
+			issues: []string{"login profile not verified: denied", "MFA not verified: throttled"},
+		},
+		{
+			name:   "failed audit",
+			creds:  RootCredentials{Error: "assume root failed"},
+			issues: []string{"audit failed: assume root failed"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := CheckBreakGlass(tt.creds)
+			assert.Equal(t, len(tt.issues) == 0, result.Compliant)
+			assert.Equal(t, tt.issues, result.Issues)
+		})
+	}
+}
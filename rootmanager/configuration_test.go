@@ -130,6 +130,15 @@ func (m *mockOrganizationsClient) DescribeOrganization(_ context.Context) (strin
 func (m *mockOrganizationsClient) ListAccounts(_ context.Context) ([]internalaws.OrganizationAccount, error) {
 	return nil, nil
 }
+func (m *mockOrganizationsClient) ListAccountsForParent(_ context.Context, _ string) ([]internalaws.OrganizationAccount, error) {
+	return nil, nil
+}
+func (m *mockOrganizationsClient) ListChildOrganizationalUnits(_ context.Context, _ string) ([]string, error) {
+	return nil, nil
+}
+func (m *mockOrganizationsClient) ListTagsForResource(_ context.Context, _ string) (map[string]string, error) {
+	return nil, nil
+}
 
 func (m *mockOrganizationsClient) EnableAWSServiceAccess(_ context.Context, _ string) error {
 	return m.enableServiceAccessErr
@@ -3,146 +3,273 @@ package rootmanager
 import (
 	"context"
 	"errors"
+	"fmt"
 	"log/slog"
-	"sync"
+	"slices"
+	"strings"
 
 	"github.com/unicrons/aws-root-manager/internal/aws"
 )
 
 // deleteAccountsCredentials deletes root credentials for a list of AWS accounts.
 // Returns a slice of DeletionResult containing the outcome for each account.
-func deleteAccountsCredentials(ctx context.Context, iam aws.IamClient, sts aws.StsClient, factory aws.IamClientFactory, creds []RootCredentials, credentialType string) ([]DeletionResult, error) {
-	if err := iam.CheckOrganizationRootAccess(ctx, false); err != nil {
+// At most opts.concurrency accounts are processed in parallel. If ctx is done
+// before every account was started, the remaining ones are marked as cancelled.
+func deleteAccountsCredentials(ctx context.Context, iam aws.IamClient, sts aws.StsClient, factory aws.IamClientFactory, creds []RootCredentials, credentialType string, opts options) ([]DeletionResult, error) {
+	accountIds := make([]string, len(creds))
+	credentialTypes := make([]string, len(creds))
+	for i, accountCreds := range creds {
+		accountIds[i] = accountCreds.AccountId
+		credentialTypes[i] = credentialType
+	}
+
+	return deleteAccounts(ctx, iam, opts, accountIds, credentialTypes, func(ctx context.Context, idx int) ([]CredentialItemResult, error) {
+		return deleteAccountCredentials(ctx, sts, factory, creds[idx], credentialType)
+	})
+}
+
+// deleteAccountsCredentialItems deletes the requested root credentials of each
+// account. Returns a slice of DeletionResult containing the outcome for each request.
+// Accounts are processed in parallel and cancelled like in deleteAccountsCredentials.
+func deleteAccountsCredentialItems(ctx context.Context, iam aws.IamClient, sts aws.StsClient, factory aws.IamClientFactory, requests []DeletionRequest, opts options) ([]DeletionResult, error) {
+	accountIds := make([]string, len(requests))
+	credentialTypes := make([]string, len(requests))
+	for i, request := range requests {
+		accountIds[i] = request.AccountId
+		credentialTypes[i] = request.credentialType()
+	}
+
+	return deleteAccounts(ctx, iam, opts, accountIds, credentialTypes, func(ctx context.Context, idx int) ([]CredentialItemResult, error) {
+		return deleteAccountCredentialItems(ctx, sts, factory, requests[idx])
+	})
+}
+
+// deleteAccounts runs the deletion of every account with deleteAccount and
+// reports the credential type deleted in each account as credentialTypes[idx],
+// along with the outcome of every credential deleteAccount attempted.
+func deleteAccounts(ctx context.Context, iam aws.IamClient, opts options, accountIds, credentialTypes []string, deleteAccount func(ctx context.Context, idx int) ([]CredentialItemResult, error)) ([]DeletionResult, error) {
+	if err := iam.CheckOrganizationRootAccess(ctx, false); err != nil && ctx.Err() == nil {
 		return nil, err
 	}
 
-	results := make([]DeletionResult, len(creds))
-	var wgAccounts sync.WaitGroup
-
-	for i, accountCredentials := range creds {
-		wgAccounts.Add(1)
-		go func(idx int, accountCreds RootCredentials) {
-			defer wgAccounts.Done()
-			if err := deleteAccountCredentials(ctx, sts, factory, accountCreds, credentialType); err != nil {
-				results[idx] = DeletionResult{
-					AccountId:      accountCreds.AccountId,
-					CredentialType: credentialType,
-					Success:        false,
-					Error:          err.Error(),
-				}
-			} else {
-				results[idx] = DeletionResult{
-					AccountId:      accountCreds.AccountId,
-					CredentialType: credentialType,
-					Success:        true,
-					Error:          "",
-				}
+	results := make([]DeletionResult, len(accountIds))
+
+	processed := forEachAccount(ctx, opts, OperationDelete, accountIds, func(ctx context.Context, idx int) bool {
+		items, err := deleteAccount(ctx, idx)
+		if err != nil {
+			results[idx] = DeletionResult{
+				AccountId:      accountIds[idx],
+				CredentialType: credentialTypes[idx],
+				Success:        false,
+				Error:          err.Error(),
+				Items:          items,
 			}
-		}(i, accountCredentials)
+			return true
+		}
+		results[idx] = DeletionResult{
+			AccountId:      accountIds[idx],
+			CredentialType: credentialTypes[idx],
+			Success:        true,
+			Error:          "",
+			Items:          items,
+		}
+		return false
+	})
+	for idx := processed; idx < len(accountIds); idx++ {
+		results[idx] = DeletionResult{
+			AccountId:      accountIds[idx],
+			CredentialType: credentialTypes[idx],
+			Cancelled:      true,
+			Error:          cancelledError(ctx),
+		}
 	}
 
-	wgAccounts.Wait()
-
 	return results, nil
 }
 
 // deleteAccountCredentials deletes root credentials for a specific account.
-func deleteAccountCredentials(ctx context.Context, sts aws.StsClient, factory aws.IamClientFactory, creds RootCredentials, credentialType string) error {
+func deleteAccountCredentials(ctx context.Context, sts aws.StsClient, factory aws.IamClientFactory, creds RootCredentials, credentialType string) ([]CredentialItemResult, error) {
 	slog.Debug("checking credentials to delete", "account_id", creds.AccountId, "credential_type", credentialType)
 
+	if err := checkCredentialsAudited(creds, credentialType); err != nil {
+		return nil, err
+	}
+
 	// Check if there are credentials to delete before assuming root
 	if !hasCredentialsToDelete(creds, credentialType) {
-		return nil
+		return nil, nil
+	}
+
+	return deleteAccountCredentialItems(ctx, sts, factory, DeletionRequest{
+		AccountId: creds.AccountId,
+		Items:     credentialItems(creds, credentialType),
+	})
+}
+
+// deleteAccountCredentialItems deletes the requested root credentials of an
+// account: the login profile first, then access keys, MFA devices and signing
+// certificates. Every credential is attempted even if another one fails, and
+// the outcome of each is returned, along with an error if any of them failed.
+func deleteAccountCredentialItems(ctx context.Context, sts aws.StsClient, factory aws.IamClientFactory, request DeletionRequest) ([]CredentialItemResult, error) {
+	if err := request.validate(); err != nil {
+		return nil, err
+	}
+	if len(request.Items) == 0 {
+		return nil, nil
 	}
 
-	awscfgDeleteRoot, err := sts.GetAssumeRootConfig(ctx, creds.AccountId, "IAMDeleteRootUserCredentials")
+	awscfgDeleteRoot, err := sts.GetAssumeRootConfig(ctx, request.AccountId, "IAMDeleteRootUserCredentials")
 	if err != nil {
-		return err
+		return nil, err
 	}
 	iamDeleteRoot := factory.NewIamClient(awscfgDeleteRoot)
 
-	if creds.LoginProfile && (credentialType == "all" || credentialType == "login") {
-		err = iamDeleteRoot.DeleteLoginProfile(ctx, creds.AccountId)
-		if err != nil {
-			return err
-		}
-	}
+	items := slices.Clone(request.Items)
+	slices.SortStableFunc(items, func(a, b CredentialItem) int {
+		return slices.Index(CredentialTypes, a.CredentialType) - slices.Index(CredentialTypes, b.CredentialType)
+	})
 
-	if len(creds.AccessKeys) > 0 && (credentialType == "all" || credentialType == "keys") {
-		err = iamDeleteRoot.DeleteAccessKeys(ctx, creds.AccountId, creds.AccessKeys)
-		if err != nil {
-			return err
+	results := make([]CredentialItemResult, len(items))
+	var failureCount int
+	for i, item := range items {
+		results[i] = CredentialItemResult{CredentialType: item.CredentialType, CredentialId: item.CredentialId, Success: true}
+		if err := deleteCredentialItem(ctx, iamDeleteRoot, request.AccountId, item); err != nil {
+			slog.Debug("failed to delete root credential", "account_id", request.AccountId, "credential", item.String(), "error", err)
+			results[i].Success = false
+			results[i].Error = err.Error()
+			failureCount++
 		}
 	}
+	if failureCount > 0 {
+		return results, fmt.Errorf("failed to delete %d of %d root credential(s)", failureCount, len(results))
+	}
+	return results, nil
+}
 
-	if len(creds.MfaDevices) > 0 && (credentialType == "all" || credentialType == "mfa") {
-		err = iamDeleteRoot.DeactivateMFADevices(ctx, creds.AccountId, creds.MfaDevices)
-		if err != nil {
-			return err
-		}
+// deleteCredentialItem deletes a single root credential of an account.
+func deleteCredentialItem(ctx context.Context, iam aws.IamClient, accountId string, item CredentialItem) error {
+	switch item.CredentialType {
+	case CheckLogin:
+		return iam.DeleteLoginProfile(ctx, accountId)
+	case CheckKeys:
+		return iam.DeleteAccessKeys(ctx, accountId, []string{item.CredentialId})
+	case CheckMFA:
+		return iam.DeactivateMFADevices(ctx, accountId, []string{item.CredentialId})
+	case CheckCertificates:
+		return iam.DeleteSigningCertificates(ctx, accountId, []string{item.CredentialId})
+	default:
+		return fmt.Errorf("invalid credential type %q", item.CredentialType)
 	}
+}
 
-	if len(creds.SigningCertificates) > 0 && (credentialType == "all" || credentialType == "certificate") {
-		err = iamDeleteRoot.DeleteSigningCertificates(ctx, creds.AccountId, creds.SigningCertificates)
-		if err != nil {
-			return err
+// checkCredentialsAudited returns an error if the audit of the account, or of any
+// check covering the credential type, failed, since its credentials are unknown.
+func checkCredentialsAudited(creds RootCredentials, credentialType string) error {
+	if creds.Error != "" {
+		return fmt.Errorf("account audit failed: %s", creds.Error)
+	}
+	for _, check := range creds.Checks {
+		if !check.Success && credentialTypeIncludes(credentialType, check.Check) {
+			return fmt.Errorf("%s audit check failed: %s", check.Check, check.Error)
 		}
 	}
-
 	return nil
 }
 
 // hasCredentialsToDelete checks if the account has credentials to delete based on the credential type.
 func hasCredentialsToDelete(creds RootCredentials, credentialType string) bool {
-	switch credentialType {
-	case "all":
-		return creds.LoginProfile || len(creds.AccessKeys) > 0 || len(creds.MfaDevices) > 0 || len(creds.SigningCertificates) > 0
-	case "login":
-		return creds.LoginProfile
-	case "keys":
-		return len(creds.AccessKeys) > 0
-	case "mfa":
-		return len(creds.MfaDevices) > 0
-	case "certificate":
-		return len(creds.SigningCertificates) > 0
-	default:
-		return false
+	return (creds.LoginProfile && credentialTypeIncludes(credentialType, CheckLogin)) ||
+		(len(creds.AccessKeys) > 0 && credentialTypeIncludes(credentialType, CheckKeys)) ||
+		(len(creds.MfaDevices) > 0 && credentialTypeIncludes(credentialType, CheckMFA)) ||
+		(len(creds.SigningCertificates) > 0 && credentialTypeIncludes(credentialType, CheckCertificates))
+}
+
+// credentialTypeIncludes reports whether credentialType, "all" or a comma-separated
+// list of credential types, covers check.
+func credentialTypeIncludes(credentialType, check string) bool {
+	return credentialType == CredentialTypeAll || slices.Contains(strings.Split(credentialType, ","), check)
+}
+
+// ValidCredentialType reports whether credentialType is "all", a single credential
+// type ("login", "keys", "mfa" or "certificate") or a comma-separated list of them,
+// as accepted by DeleteCredentials and PlanDeletion.
+func ValidCredentialType(credentialType string) bool {
+	if credentialType == CredentialTypeAll {
+		return true
+	}
+	types := strings.Split(credentialType, ",")
+	for i, t := range types {
+		if !slices.Contains(CredentialTypes, t) || slices.Contains(types[:i], t) {
+			return false
+		}
 	}
+	return true
+}
+
+// CredentialTypes are the credential types that can be deleted, in the order
+// they are deleted.
+var CredentialTypes = []string{CheckLogin, CheckKeys, CheckMFA, CheckCertificates}
+
+// accessKeyIds returns the IDs of the given access keys.
+func accessKeyIds(keys []AccessKey) []string {
+	ids := make([]string, len(keys))
+	for i, key := range keys {
+		ids[i] = key.AccessKeyId
+	}
+	return ids
+}
+
+// mfaSerialNumbers returns the serial numbers of the given MFA devices.
+func mfaSerialNumbers(devices []MFADevice) []string {
+	serials := make([]string, len(devices))
+	for i, device := range devices {
+		serials[i] = device.SerialNumber
+	}
+	return serials
+}
+
+// certificateIds returns the IDs of the given signing certificates.
+func certificateIds(certificates []SigningCertificate) []string {
+	ids := make([]string, len(certificates))
+	for i, certificate := range certificates {
+		ids[i] = certificate.CertificateId
+	}
+	return ids
 }
 
 // recoverAccountsRootPassword initiates root password recovery for a list of AWS accounts.
 // Returns a slice of RecoveryResult containing the outcome for each account.
-func recoverAccountsRootPassword(ctx context.Context, iam aws.IamClient, sts aws.StsClient, factory aws.IamClientFactory, accountIds []string) ([]RecoveryResult, error) {
-	if err := iam.CheckOrganizationRootAccess(ctx, false); err != nil {
+// At most opts.concurrency accounts are processed in parallel. If ctx is done
+// before every account was started, the remaining ones are marked as cancelled.
+func recoverAccountsRootPassword(ctx context.Context, iam aws.IamClient, sts aws.StsClient, factory aws.IamClientFactory, accountIds []string, opts options) ([]RecoveryResult, error) {
+	if err := iam.CheckOrganizationRootAccess(ctx, false); err != nil && ctx.Err() == nil {
 		return nil, err
 	}
 
 	results := make([]RecoveryResult, len(accountIds))
-	var wgAccounts sync.WaitGroup
-
-	for i, accountId := range accountIds {
-		wgAccounts.Add(1)
-		go func(idx int, accId string) {
-			defer wgAccounts.Done()
-			success, err := recoverAccountRootPassword(ctx, sts, factory, accId)
-			if err != nil {
-				results[idx] = RecoveryResult{
-					AccountId: accId,
-					Success:   false,
-					Error:     err.Error(),
-				}
-			} else {
-				results[idx] = RecoveryResult{
-					AccountId: accId,
-					Success:   success,
-					Error:     "",
-				}
+
+	processed := forEachAccount(ctx, opts, OperationRecovery, accountIds, func(ctx context.Context, idx int) bool {
+		accId := accountIds[idx]
+		success, err := recoverAccountRootPassword(ctx, sts, factory, accId)
+		if err != nil {
+			results[idx] = RecoveryResult{
+				AccountId: accId,
+				Success:   false,
+				Error:     err.Error(),
 			}
-		}(i, accountId)
+			return true
+		}
+		results[idx] = RecoveryResult{
+			AccountId: accId,
+			Success:   success,
+			Error:     "",
+		}
+		return false
+	})
+	for idx := processed; idx < len(accountIds); idx++ {
+		results[idx] = RecoveryResult{AccountId: accountIds[idx], Cancelled: true, Error: cancelledError(ctx)}
 	}
 
-	wgAccounts.Wait()
-
 	return results, nil
 }
 
@@ -18,9 +18,9 @@ func TestHasCredentialsToDelete_All(t *testing.T) {
 		want  bool
 	}{
 		{"login profile", RootCredentials{LoginProfile: true}, true},
-		{"access keys", RootCredentials{AccessKeys: []string{"key1"}}, true},
-		{"mfa devices", RootCredentials{MfaDevices: []string{"mfa1"}}, true},
-		{"certificates", RootCredentials{SigningCertificates: []string{"cert1"}}, true},
+		{"access keys", RootCredentials{AccessKeys: []AccessKey{{AccessKeyId: "key1"}}}, true},
+		{"mfa devices", RootCredentials{MfaDevices: []MFADevice{{SerialNumber: "mfa1"}}}, true},
+		{"certificates", RootCredentials{SigningCertificates: []SigningCertificate{{CertificateId: "cert1"}}}, true},
 		{"none", RootCredentials{}, false},
 	}
 	for _, tt := range tests {
@@ -33,9 +33,9 @@ func TestHasCredentialsToDelete_All(t *testing.T) {
 func TestHasCredentialsToDelete_SpecificTypes(t *testing.T) {
 	creds := RootCredentials{
 		LoginProfile:        true,
-		AccessKeys:          []string{"key1"},
-		MfaDevices:          []string{"mfa1"},
-		SigningCertificates: []string{"cert1"},
+		AccessKeys:          []AccessKey{{AccessKeyId: "key1"}},
+		MfaDevices:          []MFADevice{{SerialNumber: "mfa1"}},
+		SigningCertificates: []SigningCertificate{{CertificateId: "cert1"}},
 	}
 
 	assert.True(t, hasCredentialsToDelete(creds, "login"))
@@ -57,7 +57,7 @@ func TestDeleteAccountsCredentials_CheckAccessError(t *testing.T) {
 		checkOrgRootAccessErrs: []error{accessErr},
 	}
 
-	_, err := deleteAccountsCredentials(context.Background(), iam, nil, nil, []RootCredentials{}, "all")
+	_, err := deleteAccountsCredentials(context.Background(), iam, nil, nil, []RootCredentials{}, "all", newOptions())
 	require.Error(t, err)
 	assert.ErrorIs(t, err, accessErr)
 }
@@ -70,7 +70,7 @@ func TestDeleteAccountsCredentials_NoCredentials(t *testing.T) {
 		{AccountId: "123456789012"}, // no credentials set
 	}
 
-	results, err := deleteAccountsCredentials(context.Background(), iam, sts, nil, creds, "all")
+	results, err := deleteAccountsCredentials(context.Background(), iam, sts, nil, creds, "all", newOptions())
 	require.NoError(t, err)
 	require.Len(t, results, 1)
 	assert.True(t, results[0].Success)
@@ -86,7 +86,7 @@ func TestDeleteAccountsCredentials_DeleteLoginProfile(t *testing.T) {
 		{AccountId: "123456789012", LoginProfile: true},
 	}
 
-	results, err := deleteAccountsCredentials(context.Background(), iam, sts, factory, creds, "login")
+	results, err := deleteAccountsCredentials(context.Background(), iam, sts, factory, creds, "login", newOptions())
 	require.NoError(t, err)
 	assert.True(t, results[0].Success)
 }
@@ -98,12 +98,13 @@ func TestDeleteAccountsCredentials_DeleteAccessKeys(t *testing.T) {
 	iam := &mockIamClient{}
 
 	creds := []RootCredentials{
-		{AccountId: "123456789012", AccessKeys: []string{"AKIA123"}},
+		{AccountId: "123456789012", AccessKeys: []AccessKey{{AccessKeyId: "AKIA123"}, {AccessKeyId: "AKIA456"}}},
 	}
 
-	results, err := deleteAccountsCredentials(context.Background(), iam, sts, factory, creds, "keys")
+	results, err := deleteAccountsCredentials(context.Background(), iam, sts, factory, creds, "keys", newOptions())
 	require.NoError(t, err)
 	assert.True(t, results[0].Success)
+	assert.Equal(t, []string{"AKIA123", "AKIA456"}, rootIam.deletedAccessKeyIds)
 }
 
 func TestDeleteAccountsCredentials_DeactivateMFA(t *testing.T) {
@@ -113,12 +114,13 @@ func TestDeleteAccountsCredentials_DeactivateMFA(t *testing.T) {
 	iam := &mockIamClient{}
 
 	creds := []RootCredentials{
-		{AccountId: "123456789012", MfaDevices: []string{"arn:aws:iam::123456789012:mfa/root"}},
+		{AccountId: "123456789012", MfaDevices: []MFADevice{{SerialNumber: "arn:aws:iam::123456789012:mfa/root"}}},
 	}
 
-	results, err := deleteAccountsCredentials(context.Background(), iam, sts, factory, creds, "mfa")
+	results, err := deleteAccountsCredentials(context.Background(), iam, sts, factory, creds, "mfa", newOptions())
 	require.NoError(t, err)
 	assert.True(t, results[0].Success)
+	assert.Equal(t, []string{"arn:aws:iam::123456789012:mfa/root"}, rootIam.deactivatedMFASerials)
 }
 
 func TestDeleteAccountsCredentials_DeleteCertificates(t *testing.T) {
@@ -128,12 +130,97 @@ func TestDeleteAccountsCredentials_DeleteCertificates(t *testing.T) {
 	iam := &mockIamClient{}
 
 	creds := []RootCredentials{
-		{AccountId: "123456789012", SigningCertificates: []string{"cert-id-1"}},
+		{AccountId: "123456789012", SigningCertificates: []SigningCertificate{{CertificateId: "cert-id-1"}}},
 	}
 
-	results, err := deleteAccountsCredentials(context.Background(), iam, sts, factory, creds, "certificate")
+	results, err := deleteAccountsCredentials(context.Background(), iam, sts, factory, creds, "certificate", newOptions())
 	require.NoError(t, err)
 	assert.True(t, results[0].Success)
+	assert.Equal(t, []string{"cert-id-1"}, rootIam.deletedCertificateIds)
+}
+
+func TestDeleteAccountsCredentials_SeveralTypes(t *testing.T) {
+	rootIam := &mockIamClient{}
+	sts := &mockStsClient{}
+	factory := &mockIamClientFactory{client: rootIam}
+	iam := &mockIamClient{}
+
+	creds := []RootCredentials{
+		{
+			AccountId:           "123456789012",
+			LoginProfile:        true,
+			AccessKeys:          []AccessKey{{AccessKeyId: "AKIA123"}},
+			MfaDevices:          []MFADevice{{SerialNumber: "mfa-1"}},
+			SigningCertificates: []SigningCertificate{{CertificateId: "cert-id-1"}},
+			Checks:              []CheckResult{{Check: CheckMFA, Error: "access denied"}},
+		},
+	}
+
+	results, err := deleteAccountsCredentials(context.Background(), iam, sts, factory, creds, "login,keys,certificate", newOptions())
+	require.NoError(t, err)
+	assert.True(t, results[0].Success)
+	assert.Equal(t, "login,keys,certificate", results[0].CredentialType)
+	assert.Equal(t, int32(1), sts.assumeRootCalls.Load())
+	assert.Equal(t, []string{"AKIA123"}, rootIam.deletedAccessKeyIds)
+	assert.Equal(t, []string{"cert-id-1"}, rootIam.deletedCertificateIds)
+	assert.Empty(t, rootIam.deactivatedMFASerials)
+}
+
+func TestDeleteAccountsCredentials_ContinuesAfterItemFailure(t *testing.T) {
+	rootIam := &mockIamClient{deleteAccessKeysErr: errors.New("access denied")}
+	sts := &mockStsClient{}
+	factory := &mockIamClientFactory{client: rootIam}
+	iam := &mockIamClient{}
+
+	creds := []RootCredentials{
+		{
+			AccountId:           "123456789012",
+			LoginProfile:        true,
+			AccessKeys:          []AccessKey{{AccessKeyId: "AKIA1"}, {AccessKeyId: "AKIA2"}},
+			SigningCertificates: []SigningCertificate{{CertificateId: "cert-id-1"}},
+		},
+	}
+
+	results, err := deleteAccountsCredentials(context.Background(), iam, sts, factory, creds, "all", newOptions())
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.False(t, results[0].Success)
+	assert.Equal(t, "failed to delete 2 of 4 root credential(s)", results[0].Error)
+	assert.Equal(t, []string{"AKIA1", "AKIA2"}, rootIam.deletedAccessKeyIds)
+	assert.Equal(t, []string{"cert-id-1"}, rootIam.deletedCertificateIds)
+	assert.Equal(t, []CredentialItemResult{
+		{CredentialType: CheckLogin, Success: true},
+		{CredentialType: CheckKeys, CredentialId: "AKIA1", Error: "access denied"},
+		{CredentialType: CheckKeys, CredentialId: "AKIA2", Error: "access denied"},
+		{CredentialType: CheckCertificates, CredentialId: "cert-id-1", Success: true},
+	}, results[0].Items)
+}
+
+func TestDeleteAccountsCredentials_NoItemsAttempted(t *testing.T) {
+	sts := &mockStsClient{assumeRootErr: errors.New("assume root failed")}
+	iam := &mockIamClient{}
+
+	creds := []RootCredentials{
+		{AccountId: "123456789012", AccessKeys: []AccessKey{{AccessKeyId: "AKIA1"}}},
+		{AccountId: "234567890123"},
+	}
+
+	results, err := deleteAccountsCredentials(context.Background(), iam, sts, nil, creds, "keys", newOptions())
+	require.NoError(t, err)
+	assert.Equal(t, "assume root failed", results[0].Error)
+	assert.Empty(t, results[0].Items)
+	assert.True(t, results[1].Success)
+	assert.Empty(t, results[1].Items)
+}
+
+func TestValidCredentialType(t *testing.T) {
+	assert.True(t, ValidCredentialType("all"))
+	assert.True(t, ValidCredentialType("keys"))
+	assert.True(t, ValidCredentialType("login,keys,certificate"))
+	assert.False(t, ValidCredentialType(""))
+	assert.False(t, ValidCredentialType("all,keys"))
+	assert.False(t, ValidCredentialType("keys,keys"))
+	assert.False(t, ValidCredentialType("keys,certificates"))
 }
 
 func TestDeleteAccountsCredentials_STSError(t *testing.T) {
@@ -145,12 +232,94 @@ func TestDeleteAccountsCredentials_STSError(t *testing.T) {
 		{AccountId: "123456789012", LoginProfile: true},
 	}
 
-	results, err := deleteAccountsCredentials(context.Background(), iam, sts, nil, creds, "login")
+	results, err := deleteAccountsCredentials(context.Background(), iam, sts, nil, creds, "login", newOptions())
 	require.NoError(t, err)
 	assert.False(t, results[0].Success)
 	assert.NotEmpty(t, results[0].Error)
 }
 
+func TestDeleteAccountsCredentials_FailedAuditCheck(t *testing.T) {
+	rootIam := &mockIamClient{}
+	sts := &mockStsClient{}
+	factory := &mockIamClientFactory{client: rootIam}
+	iam := &mockIamClient{}
+
+	creds := []RootCredentials{
+		{
+			AccountId:    "123456789012",
+			LoginProfile: true,
+			Checks: []CheckResult{
+				{Check: CheckLogin, Success: true},
+				{Check: CheckKeys, Success: false, Error: "throttled"},
+			},
+		},
+	}
+
+	results, err := deleteAccountsCredentials(context.Background(), iam, sts, factory, creds, "keys", newOptions())
+	require.NoError(t, err)
+	assert.False(t, results[0].Success)
+	assert.Contains(t, results[0].Error, "throttled")
+
+	// a failed check for another credential type does not block deletion
+	results, err = deleteAccountsCredentials(context.Background(), iam, sts, factory, creds, "login", newOptions())
+	require.NoError(t, err)
+	assert.True(t, results[0].Success)
+}
+
+func TestDeleteAccountsCredentials_FailedAccountAudit(t *testing.T) {
+	iam := &mockIamClient{}
+	sts := &mockStsClient{assumeRootErr: errors.New("should not be called")}
+
+	creds := []RootCredentials{
+		{AccountId: "123456789012", Error: "assume root denied"},
+	}
+
+	results, err := deleteAccountsCredentials(context.Background(), iam, sts, nil, creds, "all", newOptions())
+	require.NoError(t, err)
+	assert.False(t, results[0].Success)
+	assert.Contains(t, results[0].Error, "assume root denied")
+}
+
+// --- deleteAccountsCredentialItems ---
+
+func TestDeleteAccountsCredentialItems_SelectedItems(t *testing.T) {
+	rootIam := &mockIamClient{}
+	sts := &mockStsClient{}
+	factory := &mockIamClientFactory{client: rootIam}
+	iam := &mockIamClient{}
+
+	requests := []DeletionRequest{
+		{AccountId: "123456789012", Items: []CredentialItem{
+			{CredentialType: CheckCertificates, CredentialId: "cert-id-1"},
+			{CredentialType: CheckKeys, CredentialId: "AKIA123"},
+		}},
+	}
+
+	results, err := deleteAccountsCredentialItems(context.Background(), iam, sts, factory, requests, newOptions())
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.True(t, results[0].Success)
+	assert.Equal(t, "keys,certificate", results[0].CredentialType)
+	assert.Equal(t, []string{"AKIA123"}, rootIam.deletedAccessKeyIds)
+	assert.Equal(t, []string{"cert-id-1"}, rootIam.deletedCertificateIds)
+	assert.Empty(t, rootIam.deactivatedMFASerials)
+}
+
+func TestDeleteAccountsCredentialItems_InvalidItem(t *testing.T) {
+	iam := &mockIamClient{}
+	sts := &mockStsClient{assumeRootErr: errors.New("should not be called")}
+
+	requests := []DeletionRequest{
+		{AccountId: "123456789012", Items: []CredentialItem{{CredentialType: CheckKeys}}},
+		{AccountId: "234567890123", Items: []CredentialItem{{CredentialType: "passwords", CredentialId: "x"}}},
+	}
+
+	results, err := deleteAccountsCredentialItems(context.Background(), iam, sts, nil, requests, newOptions())
+	require.NoError(t, err)
+	assert.Equal(t, "keys credential requested without an ID", results[0].Error)
+	assert.Equal(t, `invalid credential type "passwords"`, results[1].Error)
+}
+
 // --- recoverAccountsRootPassword ---
 
 func TestRecoverAccountsRootPassword_CheckAccessError(t *testing.T) {
@@ -159,7 +328,7 @@ func TestRecoverAccountsRootPassword_CheckAccessError(t *testing.T) {
 		checkOrgRootAccessErrs: []error{accessErr},
 	}
 
-	_, err := recoverAccountsRootPassword(context.Background(), iam, nil, nil, []string{"123456789012"})
+	_, err := recoverAccountsRootPassword(context.Background(), iam, nil, nil, []string{"123456789012"}, newOptions())
 	require.Error(t, err)
 	assert.ErrorIs(t, err, accessErr)
 }
@@ -170,7 +339,7 @@ func TestRecoverAccountsRootPassword_Success(t *testing.T) {
 	factory := &mockIamClientFactory{client: rootIam}
 	iam := &mockIamClient{}
 
-	results, err := recoverAccountsRootPassword(context.Background(), iam, sts, factory, []string{"123456789012"})
+	results, err := recoverAccountsRootPassword(context.Background(), iam, sts, factory, []string{"123456789012"}, newOptions())
 	require.NoError(t, err)
 	require.Len(t, results, 1)
 	assert.True(t, results[0].Success)
@@ -182,7 +351,7 @@ func TestRecoverAccountsRootPassword_AlreadyExists(t *testing.T) {
 	factory := &mockIamClientFactory{client: rootIam}
 	iam := &mockIamClient{}
 
-	results, err := recoverAccountsRootPassword(context.Background(), iam, sts, factory, []string{"123456789012"})
+	results, err := recoverAccountsRootPassword(context.Background(), iam, sts, factory, []string{"123456789012"}, newOptions())
 	require.NoError(t, err)
 	assert.False(t, results[0].Success)
 	assert.Empty(t, results[0].Error)
@@ -193,7 +362,7 @@ func TestRecoverAccountsRootPassword_STSError(t *testing.T) {
 	sts := &mockStsClient{assumeRootErr: stsErr}
 	iam := &mockIamClient{}
 
-	results, err := recoverAccountsRootPassword(context.Background(), iam, sts, nil, []string{"123456789012"})
+	results, err := recoverAccountsRootPassword(context.Background(), iam, sts, nil, []string{"123456789012"}, newOptions())
 	require.NoError(t, err)
 	assert.False(t, results[0].Success)
 	assert.NotEmpty(t, results[0].Error)
@@ -0,0 +1,145 @@
+package rootmanager
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"time"
+)
+
+// Finding statuses reported in Finding.Status.
+const (
+	FindingOpen     = "open"     // The credential is not covered by an active exception
+	FindingExcepted = "excepted" // The credential is covered by an active exception
+)
+
+// Exception allows an account to keep a type of root credential until it expires.
+type Exception struct {
+	AccountId      string    // AWS account ID
+	CredentialType string    // Credential type (CheckLogin, CheckKeys, CheckMFA, CheckCertificates or CredentialTypeAll)
+	Justification  string    // Why the account keeps the credential
+	Owner          string    // Who is accountable for the exception
+	Expires        time.Time // Last day the exception applies (UTC)
+}
+
+// Active reports whether the exception still applies at now. An exception
+// applies until the end of its expiry day.
+func (e Exception) Active(now time.Time) bool {
+	return now.Before(e.Expires.AddDate(0, 0, 1))
+}
+
+// matches reports whether the exception covers the credential type of the account.
+func (e Exception) matches(accountId, credentialType string) bool {
+	return e.AccountId == accountId && (e.CredentialType == CredentialTypeAll || e.CredentialType == credentialType)
+}
+
+// exceptionsFile is the json format of an exceptions file.
+type exceptionsFile struct {
+	Exceptions []struct {
+		AccountId      string `json:"account_id"`
+		CredentialType string `json:"credential_type"`
+		Justification  string `json:"justification"`
+		Owner          string `json:"owner"`
+		Expires        string `json:"expires"` // YYYY-MM-DD
+	} `json:"exceptions"`
+}
+
+// LoadExceptions reads and validates the exceptions file at path.
+func LoadExceptions(path string) ([]Exception, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read exceptions file: %w", err)
+	}
+	return ParseExceptions(data)
+}
+
+// ParseExceptions parses and validates exceptions in json format:
+//
+//	{"exceptions": [{"account_id": "123456789012", "credential_type": "login",
+//	  "justification": "break-glass", "owner": "security", "expires": "2026-12-31"}]}
+//
+// credential_type is one of login, keys, mfa, certificate or all.
+func ParseExceptions(data []byte) ([]Exception, error) {
+	var file exceptionsFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to decode exceptions file: %w", err)
+	}
+
+	validTypes := []string{CredentialTypeAll, CheckLogin, CheckKeys, CheckMFA, CheckCertificates}
+	exceptions := make([]Exception, len(file.Exceptions))
+	var errs []error
+	for i, entry := range file.Exceptions {
+		if !ValidAccountId(entry.AccountId) {
+			errs = append(errs, fmt.Errorf("exception %d: invalid account_id %q", i+1, entry.AccountId))
+		}
+		if !slices.Contains(validTypes, entry.CredentialType) {
+			errs = append(errs, fmt.Errorf("exception %d: invalid credential_type %q, expected one of %v", i+1, entry.CredentialType, validTypes))
+		}
+		if entry.Justification == "" {
+			errs = append(errs, fmt.Errorf("exception %d: justification is required", i+1))
+		}
+		if entry.Owner == "" {
+			errs = append(errs, fmt.Errorf("exception %d: owner is required", i+1))
+		}
+		expires, err := time.Parse(time.DateOnly, entry.Expires)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("exception %d: invalid expires %q, expected YYYY-MM-DD", i+1, entry.Expires))
+		}
+		exceptions[i] = Exception{
+			AccountId:      entry.AccountId,
+			CredentialType: entry.CredentialType,
+			Justification:  entry.Justification,
+			Owner:          entry.Owner,
+			Expires:        expires,
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return exceptions, nil
+}
+
+// Finding is a root credential type present in an audited account.
+type Finding struct {
+	AccountId      string     // AWS account ID
+	CredentialType string     // Credential type (CheckLogin, CheckKeys, CheckMFA, CheckCertificates)
+	Status         string     // FindingOpen or FindingExcepted
+	Exception      *Exception // Matching exception, active when excepted and expired when open (nil if none)
+}
+
+// EvaluateFindings returns a finding for every root credential type present in
+// creds, excepted when an active exception covers it. Credentials covered only
+// by expired exceptions are open findings that reference the expired exception.
+func EvaluateFindings(creds RootCredentials, exceptions []Exception, now time.Time) []Finding {
+	present := []struct {
+		credentialType string
+		present        bool
+	}{
+		{CheckLogin, creds.LoginProfile},
+		{CheckKeys, len(creds.AccessKeys) > 0},
+		{CheckMFA, len(creds.MfaDevices) > 0},
+		{CheckCertificates, len(creds.SigningCertificates) > 0},
+	}
+
+	var findings []Finding
+	for _, p := range present {
+		if !p.present {
+			continue
+		}
+		finding := Finding{AccountId: creds.AccountId, CredentialType: p.credentialType, Status: FindingOpen}
+		for _, exception := range exceptions {
+			if !exception.matches(creds.AccountId, p.credentialType) {
+				continue
+			}
+			finding.Exception = &exception
+			if exception.Active(now) {
+				finding.Status = FindingExcepted
+				break
+			}
+		}
+		findings = append(findings, finding)
+	}
+	return findings
+}
@@ -0,0 +1,72 @@
+package rootmanager
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseExceptions(t *testing.T) {
+	exceptions, err := ParseExceptions([]byte(`{"exceptions": [
+		{"account_id": "123456789012", "credential_type": "login", "justification": "break-glass", "owner": "security", "expires": "2026-12-31"}
+	]}`))
+	require.NoError(t, err)
+	assert.Equal(t, []Exception{{
+		AccountId:      "123456789012",
+		CredentialType: CheckLogin,
+		Justification:  "break-glass",
+		Owner:          "security",
+		Expires:        time.Date(2026, 12, 31, 0, 0, 0, 0, time.UTC),
+	}}, exceptions)
+}
+
+func TestParseExceptions_Invalid(t *testing.T) {
+	_, err := ParseExceptions([]byte(`{"exceptions": [
+		{"account_id": "1234", "credential_type": "password", "expires": "31/12/2026"}
+	]}`))
+	require.Error(t, err)
+	for _, msg := range []string{`invalid account_id "1234"`, `invalid credential_type "password"`, "justification is required", "owner is required", `invalid expires "31/12/2026"`} {
+		assert.Contains(t, err.Error(), msg)
+	}
+}
+
+func TestException_Active(t *testing.T) {
+	exception := Exception{Expires: time.Date(2026, 12, 31, 0, 0, 0, 0, time.UTC)}
+
+	assert.True(t, exception.Active(time.Date(2026, 12, 31, 23, 59, 0, 0, time.UTC)))
+	assert.False(t, exception.Active(time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)))
+}
+
+func TestEvaluateFindings(t *testing.T) {
+	now := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	active := Exception{AccountId: "123456789012", CredentialType: CheckLogin, Owner: "security", Expires: now.AddDate(0, 1, 0)}
+	expired := Exception{AccountId: "123456789012", CredentialType: CheckMFA, Owner: "security", Expires: now.AddDate(0, -1, 0)}
+	other := Exception{AccountId: "210987654321", CredentialType: CredentialTypeAll, Owner: "security", Expires: now.AddDate(1, 0, 0)}
+	creds := RootCredentials{
+		AccountId:    "123456789012",
+		LoginProfile: true,
+		AccessKeys:   []AccessKey{{AccessKeyId: "AKIA123"}},
+		MfaDevices:   []MFADevice{{SerialNumber: "GAHT1"}},
+	}
+
+	findings := EvaluateFindings(creds, []Exception{active, expired, other}, now)
+	assert.Equal(t, []Finding{
+		{AccountId: "123456789012", CredentialType: CheckLogin, Status: FindingExcepted, Exception: &active},
+		{AccountId: "123456789012", CredentialType: CheckKeys, Status: FindingOpen},
+		{AccountId: "123456789012", CredentialType: CheckMFA, Status: FindingOpen, Exception: &expired},
+	}, findings)
+}
+
+func TestEvaluateFindings_AllCredentialTypes(t *testing.T) {
+	now := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	exception := Exception{AccountId: "123456789012", CredentialType: CredentialTypeAll, Expires: now}
+	creds := RootCredentials{AccountId: "123456789012", LoginProfile: true, SigningCertificates: []SigningCertificate{{CertificateId: "CERT1"}}}
+
+	findings := EvaluateFindings(creds, []Exception{exception}, now)
+	require.Len(t, findings, 2)
+	for _, finding := range findings {
+		assert.Equal(t, FindingExcepted, finding.Status)
+	}
+}
@@ -3,6 +3,7 @@ package rootmanager
 import (
 	"context"
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/unicrons/aws-root-manager/internal/aws"
@@ -16,34 +17,120 @@ type manager struct {
 	factory    aws.IamClientFactory
 	s3Factory  aws.S3ClientFactory
 	sqsFactory aws.SqsClientFactory
+	opts       options
+}
+
+// options holds the settings configurable through Option.
+type options struct {
+	concurrency        int
+	assumeRootRate     float64
+	accountTimeout     time.Duration
+	assumeRootDuration time.Duration
+}
+
+const (
+	// DefaultAssumeRootRate is the default maximum number of sts:AssumeRoot calls per second.
+	DefaultAssumeRootRate = 10
+
+	// DefaultAccountTimeout is the default deadline for processing a single account.
+	DefaultAccountTimeout = 5 * time.Minute
+
+	// DefaultAssumeRootDuration is the default duration of sts:AssumeRoot sessions.
+	DefaultAssumeRootDuration = aws.DefaultAssumeRootDuration
+
+	// MinAssumeRootDuration is the minimum duration of sts:AssumeRoot sessions, so
+	// that sessions are reused for some time before they are renewed.
+	MinAssumeRootDuration = aws.MinAssumeRootDuration
+
+	// MaxAssumeRootDuration is the maximum duration AWS allows for sts:AssumeRoot sessions.
+	MaxAssumeRootDuration = aws.MaxAssumeRootDuration
+)
+
+// Option configures a RootManager created by NewRootManager.
+type Option func(*options)
+
+// WithConcurrency sets the maximum number of accounts processed in parallel
+// by multi-account operations. Values lower than 1 use DefaultConcurrency.
+func WithConcurrency(concurrency int) Option {
+	return func(o *options) {
+		o.concurrency = concurrency
+	}
+}
+
+// WithAssumeRootRate limits sts:AssumeRoot calls to requestsPerSecond. The
+// rate is lowered automatically when AWS throttles the calls and recovers as
+// they succeed again. A value of 0 disables client-side rate limiting.
+func WithAssumeRootRate(requestsPerSecond float64) Option {
+	return func(o *options) {
+		o.assumeRootRate = requestsPerSecond
+	}
+}
+
+// WithAccountTimeout sets the deadline for processing a single account in
+// multi-account operations. Values lower than or equal to 0 use DefaultAccountTimeout.
+func WithAccountTimeout(timeout time.Duration) Option {
+	return func(o *options) {
+		o.accountTimeout = timeout
+	}
+}
+
+// WithAssumeRootDuration sets the duration of sts:AssumeRoot sessions, between
+// MinAssumeRootDuration and MaxAssumeRootDuration. Sessions are renewed
+// automatically before they expire, so it only bounds how long a single set of
+// root credentials stays valid. Values lower than or equal to 0 use
+// DefaultAssumeRootDuration.
+func WithAssumeRootDuration(duration time.Duration) Option {
+	return func(o *options) {
+		o.assumeRootDuration = duration
+	}
+}
+
+// newOptions returns the default options with opts applied.
+func newOptions(opts ...Option) options {
+	o := options{concurrency: DefaultConcurrency, assumeRootRate: DefaultAssumeRootRate, accountTimeout: DefaultAccountTimeout}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.accountTimeout <= 0 {
+		o.accountTimeout = DefaultAccountTimeout
+	}
+	if o.assumeRootDuration <= 0 {
+		o.assumeRootDuration = DefaultAssumeRootDuration
+	}
+	return o
 }
 
 // newManager returns a RootManager that uses the given AWS clients and factories.
 // sts and org may be nil for callers that only use CheckRootAccess.
-func newManager(iam aws.IamClient, sts aws.StsClient, org aws.OrganizationsClient, factory aws.IamClientFactory, s3Factory aws.S3ClientFactory, sqsFactory aws.SqsClientFactory) RootManager {
-	return &manager{iam: iam, sts: sts, org: org, factory: factory, s3Factory: s3Factory, sqsFactory: sqsFactory}
+func newManager(iam aws.IamClient, sts aws.StsClient, org aws.OrganizationsClient, factory aws.IamClientFactory, s3Factory aws.S3ClientFactory, sqsFactory aws.SqsClientFactory, opts ...Option) RootManager {
+	return &manager{iam: iam, sts: sts, org: org, factory: factory, s3Factory: s3Factory, sqsFactory: sqsFactory, opts: newOptions(opts...)}
 }
 
 // NewRootManager returns a RootManager configured from the default AWS environment.
 // It loads credentials from the standard AWS credential chain (env vars, ~/.aws, IAM role).
-func NewRootManager(ctx context.Context) (RootManager, error) {
+func NewRootManager(ctx context.Context, opts ...Option) (RootManager, error) {
+	o := newOptions(opts...)
+	if o.assumeRootDuration < MinAssumeRootDuration || o.assumeRootDuration > MaxAssumeRootDuration {
+		return nil, fmt.Errorf("assume root duration %s must be between %s and %s", o.assumeRootDuration, MinAssumeRootDuration, MaxAssumeRootDuration)
+	}
 	cfg, err := aws.LoadAWSConfig(ctx)
 	if err != nil {
 		return nil, err
 	}
 	// AssumeRoot is subject to strict AWS rate limits, so the STS client uses
-	// a more aggressive retry policy than the default.
+	// a more aggressive retry policy than the default and a client-side rate limit.
 	stsCfg, err := aws.LoadAWSConfig(ctx, aws.WithRetry(10, 30*time.Second))
 	if err != nil {
 		return nil, err
 	}
 	return newManager(
 		aws.NewIamClient(cfg),
-		aws.NewStsClient(stsCfg),
+		aws.NewStsClient(stsCfg, aws.WithRateLimit(o.assumeRootRate), aws.WithRootBaseConfig(cfg), aws.WithSessionDuration(o.assumeRootDuration)),
 		aws.NewOrganizationsClient(cfg),
 		&aws.DefaultIamClientFactory{},
 		&aws.DefaultS3ClientFactory{},
 		&aws.DefaultSqsClientFactory{},
+		opts...,
 	), nil
 }
 
@@ -93,7 +180,7 @@ func (m *manager) AuditAccounts(ctx context.Context, accountIds []string) ([]Roo
 	if m.sts == nil {
 		return nil, errors.New("STS client required for audit")
 	}
-	return auditAccounts(ctx, m.iam, m.sts, m.factory, accountIds)
+	return auditAccounts(ctx, m.iam, m.sts, m.factory, accountIds, m.opts)
 }
 
 func (m *manager) CheckRootAccess(ctx context.Context) (RootAccessStatus, error) {
@@ -111,12 +198,19 @@ func (m *manager) DeleteCredentials(ctx context.Context, creds []RootCredentials
 	if m.sts == nil {
 		return nil, errors.New("STS client required for delete")
 	}
-	return deleteAccountsCredentials(ctx, m.iam, m.sts, m.factory, creds, credentialType)
+	return deleteAccountsCredentials(ctx, m.iam, m.sts, m.factory, creds, credentialType, m.opts)
+}
+
+func (m *manager) DeleteCredentialItems(ctx context.Context, requests []DeletionRequest) ([]DeletionResult, error) {
+	if m.sts == nil {
+		return nil, errors.New("STS client required for delete")
+	}
+	return deleteAccountsCredentialItems(ctx, m.iam, m.sts, m.factory, requests, m.opts)
 }
 
 func (m *manager) RecoverRootPassword(ctx context.Context, accountIds []string) ([]RecoveryResult, error) {
 	if m.sts == nil {
 		return nil, errors.New("STS client required for recovery")
 	}
-	return recoverAccountsRootPassword(ctx, m.iam, m.sts, m.factory, accountIds)
+	return recoverAccountsRootPassword(ctx, m.iam, m.sts, m.factory, accountIds, m.opts)
 }
@@ -0,0 +1,32 @@
+package rootmanager
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewRootManager_AssumeRootDurationBounds(t *testing.T) {
+	tests := []struct {
+		name     string
+		duration time.Duration
+	}{
+		{"below minimum", MinAssumeRootDuration - time.Second},
+		{"sub-second", 500 * time.Millisecond},
+		{"above maximum", MaxAssumeRootDuration + time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewRootManager(context.Background(), WithAssumeRootDuration(tt.duration))
+			assert.EqualError(t, err, "assume root duration "+tt.duration.String()+" must be between 1m0s and 15m0s")
+		})
+	}
+}
+
+func TestNewOptions_AssumeRootDuration(t *testing.T) {
+	assert.Equal(t, DefaultAssumeRootDuration, newOptions().assumeRootDuration)
+	assert.Equal(t, MinAssumeRootDuration, newOptions(WithAssumeRootDuration(MinAssumeRootDuration)).assumeRootDuration)
+	assert.Equal(t, MaxAssumeRootDuration, newOptions(WithAssumeRootDuration(MaxAssumeRootDuration)).assumeRootDuration)
+}
@@ -2,6 +2,9 @@ package rootmanager
 
 import (
 	"context"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	awssdk "github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/unicrons/aws-root-manager/internal/aws"
@@ -9,20 +12,26 @@ import (
 
 // mockIamClient implements aws.IamClient for testing.
 // checkOrgRootAccessErrs is consumed in order on each call; the last entry repeats.
+// Calls and deleted credentials are recorded under mu, since accounts are processed concurrently.
 type mockIamClient struct {
+	mu sync.Mutex
+
 	checkOrgRootAccessErrs []error
 	checkOrgRootAccessCall int
 
-	getLoginProfileResult bool
+	getLoginProfileResult aws.LoginProfile
 	getLoginProfileErr    error
 
-	listAccessKeysResult []string
+	getPasswordLastUsedResult time.Time
+	getPasswordLastUsedErr    error
+
+	listAccessKeysResult []aws.AccessKey
 	listAccessKeysErr    error
 
-	listMFADevicesResult []string
+	listMFADevicesResult []aws.MFADevice
 	listMFADevicesErr    error
 
-	listCertsResult []string
+	listCertsResult []aws.SigningCertificate
 	listCertsErr    error
 
 	deleteLoginProfileErr error
@@ -30,6 +39,10 @@ type mockIamClient struct {
 	deactivateMFAErr      error
 	deleteCertsErr        error
 
+	deletedAccessKeyIds   []string
+	deactivatedMFASerials []string
+	deletedCertificateIds []string
+
 	enableCredMgmtErr  error
 	enableSessionsErr  error
 	createLoginProfile error
@@ -39,6 +52,8 @@ func (m *mockIamClient) CheckOrganizationRootAccess(_ context.Context, _ bool) e
 	if len(m.checkOrgRootAccessErrs) == 0 {
 		return nil
 	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	idx := m.checkOrgRootAccessCall
 	if idx >= len(m.checkOrgRootAccessErrs) {
 		idx = len(m.checkOrgRootAccessErrs) - 1
@@ -46,28 +61,40 @@ func (m *mockIamClient) CheckOrganizationRootAccess(_ context.Context, _ bool) e
 	m.checkOrgRootAccessCall++
 	return m.checkOrgRootAccessErrs[idx]
 }
-func (m *mockIamClient) GetLoginProfile(_ context.Context, _ string) (bool, error) {
+func (m *mockIamClient) GetLoginProfile(_ context.Context, _ string) (aws.LoginProfile, error) {
 	return m.getLoginProfileResult, m.getLoginProfileErr
 }
+func (m *mockIamClient) GetPasswordLastUsed(_ context.Context, _ string) (time.Time, error) {
+	return m.getPasswordLastUsedResult, m.getPasswordLastUsedErr
+}
 func (m *mockIamClient) DeleteLoginProfile(_ context.Context, _ string) error {
 	return m.deleteLoginProfileErr
 }
-func (m *mockIamClient) ListAccessKeys(_ context.Context, _ string) ([]string, error) {
+func (m *mockIamClient) ListAccessKeys(_ context.Context, _ string) ([]aws.AccessKey, error) {
 	return m.listAccessKeysResult, m.listAccessKeysErr
 }
-func (m *mockIamClient) DeleteAccessKeys(_ context.Context, _ string, _ []string) error {
+func (m *mockIamClient) DeleteAccessKeys(_ context.Context, _ string, accessKeyIds []string) error {
+	m.mu.Lock()
+	m.deletedAccessKeyIds = append(m.deletedAccessKeyIds, accessKeyIds...)
+	m.mu.Unlock()
 	return m.deleteAccessKeysErr
 }
-func (m *mockIamClient) ListMFADevices(_ context.Context, _ string) ([]string, error) {
+func (m *mockIamClient) ListMFADevices(_ context.Context, _ string) ([]aws.MFADevice, error) {
 	return m.listMFADevicesResult, m.listMFADevicesErr
 }
-func (m *mockIamClient) DeactivateMFADevices(_ context.Context, _ string, _ []string) error {
+func (m *mockIamClient) DeactivateMFADevices(_ context.Context, _ string, mfaSerialNumbers []string) error {
+	m.mu.Lock()
+	m.deactivatedMFASerials = append(m.deactivatedMFASerials, mfaSerialNumbers...)
+	m.mu.Unlock()
 	return m.deactivateMFAErr
 }
-func (m *mockIamClient) ListSigningCertificates(_ context.Context, _ string) ([]string, error) {
+func (m *mockIamClient) ListSigningCertificates(_ context.Context, _ string) ([]aws.SigningCertificate, error) {
 	return m.listCertsResult, m.listCertsErr
 }
-func (m *mockIamClient) DeleteSigningCertificates(_ context.Context, _ string, _ []string) error {
+func (m *mockIamClient) DeleteSigningCertificates(_ context.Context, _ string, certificates []string) error {
+	m.mu.Lock()
+	m.deletedCertificateIds = append(m.deletedCertificateIds, certificates...)
+	m.mu.Unlock()
 	return m.deleteCertsErr
 }
 func (m *mockIamClient) EnableOrganizationsRootCredentialsManagement(_ context.Context) error {
@@ -82,10 +109,12 @@ func (m *mockIamClient) CreateLoginProfile(_ context.Context) error {
 
 // mockStsClient implements aws.StsClient for testing.
 type mockStsClient struct {
-	assumeRootErr error
+	assumeRootErr   error
+	assumeRootCalls atomic.Int32
 }
 
 func (m *mockStsClient) GetAssumeRootConfig(_ context.Context, _, _ string) (awssdk.Config, error) {
+	m.assumeRootCalls.Add(1)
 	return awssdk.Config{}, m.assumeRootErr
 }
 
@@ -0,0 +1,181 @@
+package rootmanager
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+)
+
+// CredentialItem identifies a single root credential of an account.
+type CredentialItem struct {
+	CredentialType string // Credential type (CheckLogin, CheckKeys, CheckMFA or CheckCertificates)
+	CredentialId   string // Access key ID, MFA device serial number or signing certificate ID (empty for the login profile)
+}
+
+// DeletionPlan lists the root credentials of an account that DeleteCredentials
+// would remove, based on the audit results of the account.
+type DeletionPlan struct {
+	AccountId string           // AWS account ID
+	Items     []CredentialItem // Credentials that would be deleted (empty if there is nothing to delete)
+	Error     string           // Why the credentials to delete are unknown (empty if the account was audited)
+}
+
+// PlanDeletion returns, for every audited account, the root credentials that
+// DeleteCredentials would remove for credentialType ("all", "login", "keys",
+// "mfa", "certificate" or a comma-separated list of types). No changes are
+// made. Accounts whose audit, or whose audit check covering credentialType,
+// failed get an error instead of items.
+func PlanDeletion(creds []RootCredentials, credentialType string) []DeletionPlan {
+	plans := make([]DeletionPlan, len(creds))
+	for i, accountCreds := range creds {
+		plans[i] = DeletionPlan{AccountId: accountCreds.AccountId}
+		if err := checkCredentialsAudited(accountCreds, credentialType); err != nil {
+			plans[i].Error = err.Error()
+			continue
+		}
+		plans[i].Items = credentialItems(accountCreds, credentialType)
+	}
+	return plans
+}
+
+// credentialItems returns the credentials of the account matching credentialType,
+// in the order DeleteCredentials removes them.
+func credentialItems(creds RootCredentials, credentialType string) []CredentialItem {
+	matches := func(check string) bool { return credentialTypeIncludes(credentialType, check) }
+
+	var items []CredentialItem
+	if creds.LoginProfile && matches(CheckLogin) {
+		items = append(items, CredentialItem{CredentialType: CheckLogin})
+	}
+	if matches(CheckKeys) {
+		for _, id := range accessKeyIds(creds.AccessKeys) {
+			items = append(items, CredentialItem{CredentialType: CheckKeys, CredentialId: id})
+		}
+	}
+	if matches(CheckMFA) {
+		for _, serial := range mfaSerialNumbers(creds.MfaDevices) {
+			items = append(items, CredentialItem{CredentialType: CheckMFA, CredentialId: serial})
+		}
+	}
+	if matches(CheckCertificates) {
+		for _, id := range certificateIds(creds.SigningCertificates) {
+			items = append(items, CredentialItem{CredentialType: CheckCertificates, CredentialId: id})
+		}
+	}
+	return items
+}
+
+// PlanVersion is the version of the deletion plan format written by SavePlan.
+// Plans with a newer version are rejected when loaded.
+const PlanVersion = 1
+
+// Plan is a reviewed set of root credentials to delete, saved before the
+// deletion so that it can be approved and applied later.
+type Plan struct {
+	Version        int            // Plan format version (PlanVersion when saved)
+	CreatedAt      time.Time      // When the accounts were audited for the plan
+	CredentialType string         // Credential types the plan deletes ("all", "login", "keys", "mfa", "certificate" or a comma-separated list)
+	Accounts       []DeletionPlan // Credentials to delete in every planned account
+}
+
+// SavePlan writes the plan to path as json, replacing any existing file.
+func SavePlan(path string, plan Plan) error {
+	plan.Version = PlanVersion
+	plan.CreatedAt = plan.CreatedAt.UTC().Truncate(time.Second)
+
+	data, err := json.MarshalIndent(plan, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode deletion plan: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write deletion plan: %w", err)
+	}
+	return nil
+}
+
+// LoadPlan reads and validates the plan at path.
+func LoadPlan(path string) (Plan, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Plan{}, fmt.Errorf("failed to read deletion plan: %w", err)
+	}
+
+	var plan Plan
+	if err := json.Unmarshal(data, &plan); err != nil {
+		return Plan{}, fmt.Errorf("failed to decode deletion plan: %w", err)
+	}
+	if plan.Version < 1 || plan.Version > PlanVersion {
+		return Plan{}, fmt.Errorf("deletion plan has unsupported version %d (supported up to %d)", plan.Version, PlanVersion)
+	}
+	if !ValidCredentialType(plan.CredentialType) {
+		return Plan{}, fmt.Errorf("deletion plan has invalid credential type %q, expected %q or a comma-separated list of %v",
+			plan.CredentialType, CredentialTypeAll, CredentialTypes)
+	}
+	for i, account := range plan.Accounts {
+		if !ValidAccountId(account.AccountId) {
+			return Plan{}, fmt.Errorf("deletion plan account %d: invalid account ID %q", i+1, account.AccountId)
+		}
+	}
+	return plan, nil
+}
+
+// Changes describes how the current plan of the account differs from p, for
+// instance because credentials were created or removed since p was made.
+// Returns nil when both plans delete the same credentials.
+func (p DeletionPlan) Changes(current DeletionPlan) []string {
+	if current.Error != "" {
+		return []string{"credentials unknown: " + current.Error}
+	}
+	var changes []string
+	for _, item := range current.Items {
+		if !slices.Contains(p.Items, item) {
+			changes = append(changes, fmt.Sprintf("%s appeared", item))
+		}
+	}
+	for _, item := range p.Items {
+		if !slices.Contains(current.Items, item) {
+			changes = append(changes, fmt.Sprintf("%s disappeared", item))
+		}
+	}
+	return changes
+}
+
+// String describes the credential, such as "keys AKIA..." or "login profile".
+func (i CredentialItem) String() string {
+	if i.CredentialType == CheckLogin {
+		return "login profile"
+	}
+	return i.CredentialType + " " + i.CredentialId
+}
+
+// credentialType returns the credential types of the request, comma-separated
+// in the order they are deleted.
+func (r DeletionRequest) credentialType() string {
+	var types []string
+	for _, check := range CredentialTypes {
+		if slices.ContainsFunc(r.Items, func(item CredentialItem) bool { return item.CredentialType == check }) {
+			types = append(types, check)
+		}
+	}
+	return strings.Join(types, ",")
+}
+
+// validate returns an error if an item of the request has an unknown credential
+// type, or has no ID while not being the login profile.
+func (r DeletionRequest) validate() error {
+	for _, item := range r.Items {
+		switch item.CredentialType {
+		case CheckLogin:
+		case CheckKeys, CheckMFA, CheckCertificates:
+			if item.CredentialId == "" {
+				return fmt.Errorf("%s credential requested without an ID", item.CredentialType)
+			}
+		default:
+			return fmt.Errorf("invalid credential type %q", item.CredentialType)
+		}
+	}
+	return nil
+}
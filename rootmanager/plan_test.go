@@ -0,0 +1,111 @@
+package rootmanager
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlanDeletion(t *testing.T) {
+	creds := []RootCredentials{
+		{
+			AccountId:           "111111111111",
+			LoginProfile:        true,
+			AccessKeys:          []AccessKey{{AccessKeyId: "AKIA1"}, {AccessKeyId: "AKIA2"}},
+			MfaDevices:          []MFADevice{{SerialNumber: "arn:aws:iam::111111111111:mfa/root"}},
+			SigningCertificates: []SigningCertificate{{CertificateId: "cert1"}},
+		},
+		{AccountId: "222222222222"},
+		{AccountId: "333333333333", Error: "access denied"},
+		{
+			AccountId:  "444444444444",
+			AccessKeys: []AccessKey{{AccessKeyId: "AKIA4"}},
+			Checks:     []CheckResult{{Check: CheckKeys, Success: true}, {Check: CheckMFA, Error: "throttled"}},
+		},
+	}
+
+	t.Run("all", func(t *testing.T) {
+		plans := PlanDeletion(creds, "all")
+		assert.Equal(t, []DeletionPlan{
+			{AccountId: "111111111111", Items: []CredentialItem{
+				{CredentialType: CheckLogin},
+				{CredentialType: CheckKeys, CredentialId: "AKIA1"},
+				{CredentialType: CheckKeys, CredentialId: "AKIA2"},
+				{CredentialType: CheckMFA, CredentialId: "arn:aws:iam::111111111111:mfa/root"},
+				{CredentialType: CheckCertificates, CredentialId: "cert1"},
+			}},
+			{AccountId: "222222222222"},
+			{AccountId: "333333333333", Error: "account audit failed: access denied"},
+			{AccountId: "444444444444", Error: "mfa audit check failed: throttled"},
+		}, plans)
+	})
+
+	t.Run("keys", func(t *testing.T) {
+		plans := PlanDeletion(creds, "keys")
+		assert.Equal(t, []CredentialItem{
+			{CredentialType: CheckKeys, CredentialId: "AKIA1"},
+			{CredentialType: CheckKeys, CredentialId: "AKIA2"},
+		}, plans[0].Items)
+		assert.Equal(t, []CredentialItem{{CredentialType: CheckKeys, CredentialId: "AKIA4"}}, plans[3].Items)
+		assert.Empty(t, plans[3].Error)
+	})
+}
+
+func TestSavePlan_LoadPlan(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "plan.json")
+	plan := Plan{
+		CreatedAt:      time.Date(2026, 10, 1, 12, 30, 45, 500, time.UTC),
+		CredentialType: "all",
+		Accounts: []DeletionPlan{
+			{AccountId: "111111111111", Items: []CredentialItem{{CredentialType: CheckLogin}, {CredentialType: CheckKeys, CredentialId: "AKIA1"}}},
+			{AccountId: "222222222222", Error: "account audit failed: access denied"},
+		},
+	}
+	require.NoError(t, SavePlan(path, plan))
+
+	loaded, err := LoadPlan(path)
+	require.NoError(t, err)
+	assert.Equal(t, PlanVersion, loaded.Version)
+	assert.Equal(t, time.Date(2026, 10, 1, 12, 30, 45, 0, time.UTC), loaded.CreatedAt)
+	assert.Equal(t, plan.Accounts, loaded.Accounts)
+}
+
+func TestLoadPlan_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		wantErr string
+	}{
+		{"malformed", `{"Version": 1,`, "failed to decode deletion plan"},
+		{"newer version", `{"Version": 2, "CredentialType": "all"}`, "unsupported version 2"},
+		{"credential type", `{"Version": 1, "CredentialType": "passwords"}`, `invalid credential type "passwords"`},
+		{"repeated credential type", `{"Version": 1, "CredentialType": "keys,keys"}`, `invalid credential type "keys,keys"`},
+		{"account ID", `{"Version": 1, "CredentialType": "keys", "Accounts": [{"AccountId": "1234"}]}`, `invalid account ID "1234"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "plan.json")
+			require.NoError(t, os.WriteFile(path, []byte(tt.data), 0o600))
+			_, err := LoadPlan(path)
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestDeletionPlan_Changes(t *testing.T) {
+	planned := DeletionPlan{AccountId: "111111111111", Items: []CredentialItem{
+		{CredentialType: CheckLogin},
+		{CredentialType: CheckKeys, CredentialId: "AKIA1"},
+	}}
+
+	assert.Nil(t, planned.Changes(planned))
+	assert.Equal(t, []string{"keys AKIA2 appeared", "login profile disappeared"}, planned.Changes(DeletionPlan{Items: []CredentialItem{
+		{CredentialType: CheckKeys, CredentialId: "AKIA1"},
+		{CredentialType: CheckKeys, CredentialId: "AKIA2"},
+	}}))
+	assert.Equal(t, []string{"credentials unknown: account audit failed: throttled"}, planned.Changes(DeletionPlan{Error: "account audit failed: throttled"}))
+}
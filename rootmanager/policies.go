@@ -10,9 +10,12 @@ import (
 const (
 	s3UnlockTaskPolicy  = "S3UnlockBucketPolicy"
 	sqsUnlockTaskPolicy = "SQSUnlockQueuePolicy"
+)
 
-	resourceTypeS3Bucket = "s3-bucket"
-	resourceTypeSqsQueue = "sqs-queue"
+// Resource types reported in PolicyDeletionResult.ResourceType.
+const (
+	ResourceTypeS3Bucket = "s3-bucket"
+	ResourceTypeSQSQueue = "sqs-queue"
 )
 
 func getS3BucketPolicy(ctx context.Context, sts aws.StsClient, factory aws.S3ClientFactory, accountId, bucketName string) (string, error) {
@@ -40,7 +43,7 @@ func deleteS3BucketPolicy(ctx context.Context, sts aws.StsClient, factory aws.S3
 
 	result := PolicyDeletionResult{
 		AccountId:    accountId,
-		ResourceType: resourceTypeS3Bucket,
+		ResourceType: ResourceTypeS3Bucket,
 		ResourceName: bucketName,
 	}
 
@@ -84,7 +87,7 @@ func deleteSQSQueuePolicy(ctx context.Context, sts aws.StsClient, factory aws.Sq
 
 	result := PolicyDeletionResult{
 		AccountId:    accountId,
-		ResourceType: resourceTypeSqsQueue,
+		ResourceType: ResourceTypeSQSQueue,
 		ResourceName: queueUrl,
 	}
 
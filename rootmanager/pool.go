@@ -0,0 +1,78 @@
+package rootmanager
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// DefaultConcurrency is the number of accounts processed in parallel when no
+// concurrency is configured.
+const DefaultConcurrency = 10
+
+// forEach calls fn for every index in [0, n) from a bounded pool of workers,
+// running at most concurrency calls at once. Indexes are scheduled in order and
+// scheduling stops once ctx is done; calls already started are not interrupted.
+// It returns, once all started calls are done, the number of indexes scheduled.
+func forEach(ctx context.Context, concurrency, n int, fn func(i int)) int {
+	if concurrency <= 0 {
+		concurrency = DefaultConcurrency
+	}
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+
+	for range min(concurrency, n) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				fn(i)
+			}
+		}()
+	}
+
+	scheduled := 0
+schedule:
+	for scheduled < n {
+		if ctx.Err() != nil {
+			break
+		}
+		select {
+		case indexes <- scheduled:
+			scheduled++
+		case <-ctx.Done():
+			break schedule
+		}
+	}
+	close(indexes)
+
+	wg.Wait()
+	return scheduled
+}
+
+// forEachAccount calls fn for every account through the worker pool and reports
+// the progress of operation to the ProgressFunc attached to ctx. fn returns
+// whether processing the account failed.
+//
+// Once ctx is done no more accounts are started, but accounts in flight run to
+// completion: fn gets a context that is not cancelled with ctx and is instead
+// bounded by the per-account timeout. It returns the number of accounts
+// processed; accounts from that index on were cancelled.
+func forEachAccount(ctx context.Context, opts options, operation string, accountIds []string, fn func(ctx context.Context, idx int) (failed bool)) int {
+	tracker := newProgressTracker(ctx, operation, len(accountIds))
+	return forEach(ctx, opts.concurrency, len(accountIds), func(idx int) {
+		accountCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), opts.accountTimeout)
+		defer cancel()
+
+		tracker.start(accountIds[idx])
+		failed := fn(accountCtx, idx)
+		tracker.finish(accountIds[idx], failed)
+	})
+}
+
+// cancelledError returns the error reported for accounts that were not
+// processed because ctx was done.
+func cancelledError(ctx context.Context) string {
+	return fmt.Sprintf("not processed: %v", context.Cause(ctx))
+}
@@ -0,0 +1,118 @@
+package rootmanager
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestForEach_VisitsEveryIndex(t *testing.T) {
+	var mu sync.Mutex
+	visited := make(map[int]int)
+
+	forEach(context.Background(), 3, 20, func(i int) {
+		mu.Lock()
+		defer mu.Unlock()
+		visited[i]++
+	})
+
+	assert.Len(t, visited, 20)
+	for i := range 20 {
+		assert.Equal(t, 1, visited[i])
+	}
+}
+
+func TestForEach_BoundsConcurrency(t *testing.T) {
+	var running, maxRunning atomic.Int32
+
+	forEach(context.Background(), 2, 10, func(_ int) {
+		current := running.Add(1)
+		for {
+			previous := maxRunning.Load()
+			if current <= previous || maxRunning.CompareAndSwap(previous, current) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		running.Add(-1)
+	})
+
+	assert.LessOrEqual(t, maxRunning.Load(), int32(2))
+}
+
+func TestForEach_NoItems(t *testing.T) {
+	called := false
+	scheduled := forEach(context.Background(), 0, 0, func(_ int) { called = true })
+	assert.False(t, called)
+	assert.Zero(t, scheduled)
+}
+
+func TestForEach_StopsSchedulingWhenCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var calls atomic.Int32
+	scheduled := forEach(ctx, 1, 10, func(i int) {
+		calls.Add(1)
+		if i == 2 {
+			cancel()
+		}
+	})
+
+	assert.Equal(t, 3, scheduled)
+	assert.Equal(t, int32(3), calls.Load())
+}
+
+func TestForEachAccount_ReportsProgress(t *testing.T) {
+	var events []ProgressEvent
+	ctx := WithProgress(context.Background(), func(e ProgressEvent) {
+		events = append(events, e)
+	})
+
+	accounts := []string{"111111111111", "222222222222", "333333333333"}
+	forEachAccount(ctx, newOptions(WithConcurrency(1)), OperationAudit, accounts, func(_ context.Context, idx int) bool {
+		return idx == 1
+	})
+
+	require.Len(t, events, 6)
+	for _, e := range events {
+		assert.Equal(t, OperationAudit, e.Operation)
+		assert.Equal(t, 3, e.Total)
+	}
+	// with a single worker every account starts and finishes before the next one
+	assert.Equal(t, ProgressEvent{Operation: OperationAudit, AccountId: "111111111111", InFlight: 1, Total: 3}, events[0])
+	assert.Equal(t, ProgressEvent{Operation: OperationAudit, AccountId: "222222222222", Completed: true, Failed: true, Done: 2, Failures: 1, Total: 3}, events[3])
+	assert.Equal(t, ProgressEvent{Operation: OperationAudit, AccountId: "333333333333", Completed: true, Done: 3, Failures: 1, Total: 3}, events[5])
+}
+
+func TestForEachAccount_WithoutProgress(t *testing.T) {
+	var calls atomic.Int32
+	forEachAccount(context.Background(), newOptions(WithConcurrency(2)), OperationDelete, []string{"111111111111", "222222222222"}, func(_ context.Context, _ int) bool {
+		calls.Add(1)
+		return false
+	})
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+func TestForEachAccount_InFlightAccountsOutliveCancellation(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var accountErrs []error
+	opts := newOptions(WithConcurrency(1), WithAccountTimeout(time.Minute))
+	processed := forEachAccount(ctx, opts, OperationAudit, []string{"111111111111", "222222222222"}, func(accountCtx context.Context, _ int) bool {
+		cancel()
+		accountErrs = append(accountErrs, accountCtx.Err())
+		_, hasDeadline := accountCtx.Deadline()
+		assert.True(t, hasDeadline)
+		return false
+	})
+
+	assert.Equal(t, 1, processed)
+	assert.Equal(t, []error{nil}, accountErrs)
+}
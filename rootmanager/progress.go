@@ -0,0 +1,90 @@
+package rootmanager
+
+import (
+	"context"
+	"sync"
+)
+
+// Operations reported in ProgressEvent.Operation.
+const (
+	OperationAudit    = "audit"
+	OperationDelete   = "delete"
+	OperationRecovery = "recovery"
+)
+
+// ProgressEvent reports the progress of a multi-account operation. An event is
+// emitted when an account starts processing and when it finishes.
+type ProgressEvent struct {
+	Operation string // Operation in progress (OperationAudit, OperationDelete, OperationRecovery)
+	AccountId string // Account the event refers to
+	Completed bool   // false when the account started processing, true when it finished
+	Failed    bool   // Whether the account finished with an error
+	Done      int    // Accounts finished so far, including failed ones
+	Failures  int    // Accounts finished with an error so far
+	InFlight  int    // Accounts currently being processed
+	Total     int    // Accounts in the operation
+}
+
+// ProgressFunc receives progress events. Calls are serialized, so it does not
+// need to be safe for concurrent use, but it should return quickly.
+type ProgressFunc func(ProgressEvent)
+
+type progressKey struct{}
+
+// WithProgress returns a copy of ctx that makes AuditAccounts, DeleteCredentials
+// and RecoverRootPassword report the progress of each account to fn.
+func WithProgress(ctx context.Context, fn ProgressFunc) context.Context {
+	return context.WithValue(ctx, progressKey{}, fn)
+}
+
+// progressTracker counts the accounts of an operation and reports every change.
+type progressTracker struct {
+	mu    sync.Mutex
+	fn    ProgressFunc
+	event ProgressEvent
+}
+
+// newProgressTracker returns a tracker reporting to the ProgressFunc attached
+// to ctx, if any.
+func newProgressTracker(ctx context.Context, operation string, total int) *progressTracker {
+	fn, _ := ctx.Value(progressKey{}).(ProgressFunc)
+	return &progressTracker{
+		fn:    fn,
+		event: ProgressEvent{Operation: operation, Total: total},
+	}
+}
+
+// start records that an account started processing.
+func (t *progressTracker) start(accountId string) {
+	if t.fn == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.event.InFlight++
+	t.report(accountId, false, false)
+}
+
+// finish records that an account finished processing.
+func (t *progressTracker) finish(accountId string, failed bool) {
+	if t.fn == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.event.InFlight--
+	t.event.Done++
+	if failed {
+		t.event.Failures++
+	}
+	t.report(accountId, true, failed)
+}
+
+// report sends the current counts. Must be called with mu held.
+func (t *progressTracker) report(accountId string, completed, failed bool) {
+	event := t.event
+	event.AccountId = accountId
+	event.Completed = completed
+	event.Failed = failed
+	t.fn(event)
+}
@@ -0,0 +1,228 @@
+package rootmanager
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SnapshotVersion is the version of the snapshot format written by SnapshotStore.
+// Snapshots with a newer version are rejected when loaded.
+const SnapshotVersion = 1
+
+// snapshotIdLayout is the time layout of snapshot IDs. Snapshots saved in the
+// same second get a "-2", "-3"... suffix after the time.
+const snapshotIdLayout = "20060102T150405Z"
+
+// snapshotFilePrefix and snapshotFileSuffix surround the ID in snapshot file names.
+const (
+	snapshotFilePrefix = "audit-"
+	snapshotFileSuffix = ".json"
+)
+
+// ErrSnapshotNotFound indicates the requested snapshot does not exist in the store.
+var ErrSnapshotNotFound = errors.New("snapshot not found")
+
+// ErrInvalidSnapshotId indicates the requested snapshot ID is not in the
+// format of the IDs returned by Save.
+var ErrInvalidSnapshotId = errors.New("invalid snapshot ID")
+
+// Snapshot is the result of an audit saved at a point in time.
+type Snapshot struct {
+	Version   int               // Snapshot format version (SnapshotVersion when saved)
+	Timestamp time.Time         // When the audit was taken
+	Accounts  []RootCredentials // Audit results of every audited account
+
+	id string // ID the snapshot was loaded with
+}
+
+// ID returns the identifier of the snapshot: the ID it was loaded with, or
+// one derived from its timestamp.
+func (s Snapshot) ID() string {
+	if s.id != "" {
+		return s.id
+	}
+	return s.Timestamp.UTC().Format(snapshotIdLayout)
+}
+
+// Account returns the audit result of the given account and whether the
+// account is part of the snapshot.
+func (s Snapshot) Account(accountId string) (RootCredentials, bool) {
+	for _, creds := range s.Accounts {
+		if creds.AccountId == accountId {
+			return creds, true
+		}
+	}
+	return RootCredentials{}, false
+}
+
+// SnapshotStore stores audit snapshots as timestamped json files in a local directory.
+type SnapshotStore struct {
+	dir string
+}
+
+// NewSnapshotStore returns a SnapshotStore that keeps snapshots in dir.
+// The directory is created on the first Save.
+func NewSnapshotStore(dir string) *SnapshotStore {
+	return &SnapshotStore{dir: dir}
+}
+
+// Save writes the snapshot to the store and returns its ID. An existing
+// snapshot is never overwritten: a snapshot taken in the same second as an
+// existing one gets the next free suffix in its ID.
+func (s *SnapshotStore) Save(snapshot Snapshot) (string, error) {
+	snapshot.Version = SnapshotVersion
+	snapshot.Timestamp = snapshot.Timestamp.UTC().Truncate(time.Second)
+
+	if err := os.MkdirAll(s.dir, 0o700); err != nil {
+		return "", fmt.Errorf("failed to create snapshot directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to encode snapshot: %w", err)
+	}
+
+	var id string
+	var file *os.File
+	for n := 1; ; n++ {
+		id = snapshot.ID()
+		if n > 1 {
+			id = fmt.Sprintf("%s-%d", id, n)
+		}
+		file, err = os.OpenFile(s.path(id), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+		if errors.Is(err, fs.ErrExist) {
+			continue
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to create snapshot %s: %w", id, err)
+		}
+		break
+	}
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		return "", fmt.Errorf("failed to write snapshot %s: %w", id, err)
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(file.Name())
+		return "", fmt.Errorf("failed to write snapshot %s: %w", id, err)
+	}
+
+	return id, nil
+}
+
+// List returns the IDs of the stored snapshots, oldest first.
+func (s *SnapshotStore) List() ([]string, error) {
+	entries, err := os.ReadDir(s.dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot directory: %w", err)
+	}
+
+	var ids []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, snapshotFilePrefix) || !strings.HasSuffix(name, snapshotFileSuffix) {
+			continue
+		}
+		id := strings.TrimSuffix(strings.TrimPrefix(name, snapshotFilePrefix), snapshotFileSuffix)
+		if _, _, ok := parseSnapshotId(id); !ok {
+			continue
+		}
+		ids = append(ids, id)
+	}
+	slices.SortFunc(ids, func(a, b string) int {
+		aTime, aSeq, _ := parseSnapshotId(a)
+		bTime, bSeq, _ := parseSnapshotId(b)
+		if c := aTime.Compare(bTime); c != 0 {
+			return c
+		}
+		return aSeq - bSeq
+	})
+	return ids, nil
+}
+
+// parseSnapshotId returns the time of a snapshot ID and its position among the
+// snapshots saved in the same second (1 for the first one, without suffix).
+func parseSnapshotId(id string) (time.Time, int, bool) {
+	base, suffix, found := strings.Cut(id, "-")
+	timestamp, err := time.Parse(snapshotIdLayout, base)
+	if err != nil {
+		return time.Time{}, 0, false
+	}
+	if !found {
+		return timestamp, 1, true
+	}
+	seq, err := strconv.Atoi(suffix)
+	if err != nil || seq < 2 || strconv.Itoa(seq) != suffix {
+		return time.Time{}, 0, false
+	}
+	return timestamp, seq, true
+}
+
+// Load reads the snapshot with the given ID.
+func (s *SnapshotStore) Load(id string) (Snapshot, error) {
+	// the ID is part of the file path, so only IDs in the expected format are read
+	if _, _, ok := parseSnapshotId(id); !ok {
+		return Snapshot{}, fmt.Errorf("%w %q: expected a snapshot ID such as 20250101T020000Z", ErrInvalidSnapshotId, id)
+	}
+	data, err := os.ReadFile(s.path(id))
+	if errors.Is(err, fs.ErrNotExist) {
+		return Snapshot{}, fmt.Errorf("%w: %s", ErrSnapshotNotFound, id)
+	}
+	if err != nil {
+		return Snapshot{}, fmt.Errorf("failed to read snapshot %s: %w", id, err)
+	}
+
+	var snapshot Snapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return Snapshot{}, fmt.Errorf("failed to decode snapshot %s: %w", id, err)
+	}
+	if snapshot.Version < 1 || snapshot.Version > SnapshotVersion {
+		return Snapshot{}, fmt.Errorf("snapshot %s has unsupported version %d (supported up to %d)", id, snapshot.Version, SnapshotVersion)
+	}
+	snapshot.id = id
+	return snapshot, nil
+}
+
+// AccountHistory returns the audit results of the given account in every
+// stored snapshot that includes it, oldest first.
+func (s *SnapshotStore) AccountHistory(accountId string) ([]AccountSnapshot, error) {
+	ids, err := s.List()
+	if err != nil {
+		return nil, err
+	}
+
+	var history []AccountSnapshot
+	for _, id := range ids {
+		snapshot, err := s.Load(id)
+		if err != nil {
+			return nil, err
+		}
+		if creds, ok := snapshot.Account(accountId); ok {
+			history = append(history, AccountSnapshot{SnapshotId: id, Timestamp: snapshot.Timestamp, Credentials: creds})
+		}
+	}
+	return history, nil
+}
+
+func (s *SnapshotStore) path(id string) string {
+	return filepath.Join(s.dir, snapshotFilePrefix+id+snapshotFileSuffix)
+}
+
+// AccountSnapshot is the audit result of an account in a given snapshot.
+type AccountSnapshot struct {
+	SnapshotId  string          // ID of the snapshot
+	Timestamp   time.Time       // When the snapshot was taken
+	Credentials RootCredentials // Audit result of the account
+}
@@ -0,0 +1,153 @@
+package rootmanager
+
+import (
+	"fmt"
+	"slices"
+	"time"
+)
+
+// Kinds of change reported in CredentialChange.Change.
+const (
+	ChangeAppeared    = "appeared"     // The credential exists in the newer snapshot only
+	ChangeDisappeared = "disappeared"  // The credential exists in the older snapshot only
+	ChangeModified    = "changed"      // The credential exists in both snapshots with different attributes
+	ChangeNotCompared = "not compared" // The credentials could not be compared, see Details
+)
+
+// CredentialChange describes how a root credential of an account changed between two snapshots.
+type CredentialChange struct {
+	AccountId      string // AWS account ID
+	CredentialType string // Credential type (CheckLogin, CheckKeys, CheckMFA, CheckCertificates); empty for the whole account
+	CredentialId   string // Access key ID, MFA serial number or certificate ID (empty for the login profile)
+	Change         string // Kind of change (ChangeAppeared, ChangeDisappeared, ChangeModified, ChangeNotCompared)
+	Details        string // Description of the change
+}
+
+// DiffSnapshots returns the root credentials that appeared, disappeared or
+// changed between the from and to snapshots, ordered by account. Accounts that
+// are missing from a snapshot or whose audit failed, and checks that failed,
+// are reported as ChangeNotCompared instead of as credential changes.
+func DiffSnapshots(from, to Snapshot) []CredentialChange {
+	var accountIds []string
+	for _, creds := range append(slices.Clone(from.Accounts), to.Accounts...) {
+		if !slices.Contains(accountIds, creds.AccountId) {
+			accountIds = append(accountIds, creds.AccountId)
+		}
+	}
+	slices.Sort(accountIds)
+
+	var changes []CredentialChange
+	for _, accountId := range accountIds {
+		fromCreds, inFrom := from.Account(accountId)
+		toCreds, inTo := to.Account(accountId)
+		switch {
+		case !inFrom:
+			changes = append(changes, accountNotCompared(accountId, "not in snapshot "+from.ID()))
+		case !inTo:
+			changes = append(changes, accountNotCompared(accountId, "not in snapshot "+to.ID()))
+		case fromCreds.Error != "":
+			changes = append(changes, accountNotCompared(accountId, fmt.Sprintf("audit failed in snapshot %s: %s", from.ID(), fromCreds.Error)))
+		case toCreds.Error != "":
+			changes = append(changes, accountNotCompared(accountId, fmt.Sprintf("audit failed in snapshot %s: %s", to.ID(), toCreds.Error)))
+		default:
+			changes = append(changes, diffAccount(from, to, fromCreds, toCreds)...)
+		}
+	}
+	return changes
+}
+
+func accountNotCompared(accountId, details string) CredentialChange {
+	return CredentialChange{AccountId: accountId, Change: ChangeNotCompared, Details: details}
+}
+
+// diffAccount compares the credentials of an account audited in both snapshots.
+func diffAccount(from, to Snapshot, fromCreds, toCreds RootCredentials) []CredentialChange {
+	accountId := fromCreds.AccountId
+	var changes []CredentialChange
+
+	for _, check := range []string{CheckLogin, CheckKeys, CheckMFA, CheckCertificates} {
+		if checkErr := fromCreds.CheckError(check); checkErr != "" {
+			changes = append(changes, CredentialChange{AccountId: accountId, CredentialType: check, Change: ChangeNotCompared, Details: fmt.Sprintf("check failed in snapshot %s: %s", from.ID(), checkErr)})
+			continue
+		}
+		if checkErr := toCreds.CheckError(check); checkErr != "" {
+			changes = append(changes, CredentialChange{AccountId: accountId, CredentialType: check, Change: ChangeNotCompared, Details: fmt.Sprintf("check failed in snapshot %s: %s", to.ID(), checkErr)})
+			continue
+		}
+
+		switch check {
+		case CheckLogin:
+			changes = append(changes, diffLoginProfile(fromCreds, toCreds)...)
+		case CheckKeys:
+			changes = append(changes, diffItems(accountId, check, fromCreds.AccessKeys, toCreds.AccessKeys,
+				func(key AccessKey) string { return key.AccessKeyId },
+				func(before, after AccessKey) string { return statusChange(before.Status, after.Status) })...)
+		case CheckMFA:
+			changes = append(changes, diffItems(accountId, check, fromCreds.MfaDevices, toCreds.MfaDevices,
+				func(device MFADevice) string { return device.SerialNumber },
+				func(before, after MFADevice) string {
+					return dateChange("enabled", before.EnableDate, after.EnableDate)
+				})...)
+		case CheckCertificates:
+			changes = append(changes, diffItems(accountId, check, fromCreds.SigningCertificates, toCreds.SigningCertificates,
+				func(certificate SigningCertificate) string { return certificate.CertificateId },
+				func(before, after SigningCertificate) string { return statusChange(before.Status, after.Status) })...)
+		}
+	}
+	return changes
+}
+
+// diffLoginProfile compares the login profile of an account between two snapshots.
+func diffLoginProfile(fromCreds, toCreds RootCredentials) []CredentialChange {
+	change := CredentialChange{AccountId: fromCreds.AccountId, CredentialType: CheckLogin}
+	switch {
+	case !fromCreds.LoginProfile && toCreds.LoginProfile:
+		change.Change = ChangeAppeared
+		change.Details = "created " + toCreds.LoginProfileCreateDate.Format(time.DateOnly)
+	case fromCreds.LoginProfile && !toCreds.LoginProfile:
+		change.Change = ChangeDisappeared
+	case fromCreds.LoginProfile && !fromCreds.LoginProfileCreateDate.Equal(toCreds.LoginProfileCreateDate):
+		change.Change = ChangeModified
+		change.Details = dateChange("created", fromCreds.LoginProfileCreateDate, toCreds.LoginProfileCreateDate)
+	default:
+		return nil
+	}
+	return []CredentialChange{change}
+}
+
+// diffItems compares the credentials of one type, matched by the ID returned by
+// id. modified describes the differences of a credential present in both
+// snapshots, or returns an empty string if it did not change.
+func diffItems[T any](accountId, credentialType string, from, to []T, id func(T) string, modified func(before, after T) string) []CredentialChange {
+	var changes []CredentialChange
+	for _, before := range from {
+		idx := slices.IndexFunc(to, func(item T) bool { return id(item) == id(before) })
+		if idx < 0 {
+			changes = append(changes, CredentialChange{AccountId: accountId, CredentialType: credentialType, CredentialId: id(before), Change: ChangeDisappeared})
+			continue
+		}
+		if details := modified(before, to[idx]); details != "" {
+			changes = append(changes, CredentialChange{AccountId: accountId, CredentialType: credentialType, CredentialId: id(before), Change: ChangeModified, Details: details})
+		}
+	}
+	for _, after := range to {
+		if !slices.ContainsFunc(from, func(item T) bool { return id(item) == id(after) }) {
+			changes = append(changes, CredentialChange{AccountId: accountId, CredentialType: credentialType, CredentialId: id(after), Change: ChangeAppeared})
+		}
+	}
+	return changes
+}
+
+func statusChange(before, after string) string {
+	if before == after {
+		return ""
+	}
+	return fmt.Sprintf("status %s -> %s", before, after)
+}
+
+func dateChange(attribute string, before, after time.Time) string {
+	if before.Equal(after) {
+		return ""
+	}
+	return fmt.Sprintf("%s %s -> %s", attribute, before.Format(time.DateOnly), after.Format(time.DateOnly))
+}
@@ -0,0 +1,161 @@
+package rootmanager
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSnapshotStore_SaveListLoad(t *testing.T) {
+	store := NewSnapshotStore(filepath.Join(t.TempDir(), "snapshots"))
+	first := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	second := first.Add(24 * time.Hour)
+
+	secondId, err := store.Save(Snapshot{Timestamp: second, Accounts: []RootCredentials{{AccountId: "111111111111", LoginProfile: true}}})
+	require.NoError(t, err)
+	firstId, err := store.Save(Snapshot{Timestamp: first, Accounts: []RootCredentials{{AccountId: "111111111111"}}})
+	require.NoError(t, err)
+
+	ids, err := store.List()
+	require.NoError(t, err)
+	assert.Equal(t, []string{firstId, secondId}, ids)
+	assert.Equal(t, "20250102T100000Z", secondId)
+
+	snapshot, err := store.Load(secondId)
+	require.NoError(t, err)
+	assert.Equal(t, SnapshotVersion, snapshot.Version)
+	assert.True(t, snapshot.Timestamp.Equal(second))
+	assert.Equal(t, []RootCredentials{{AccountId: "111111111111", LoginProfile: true}}, snapshot.Accounts)
+}
+
+func TestSnapshotStore_SaveSameSecond(t *testing.T) {
+	store := NewSnapshotStore(t.TempDir())
+	timestamp := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	var saved []string
+	for i := range 11 {
+		id, err := store.Save(Snapshot{Timestamp: timestamp.Add(time.Duration(i) * time.Millisecond), Accounts: []RootCredentials{{AccountId: fmt.Sprint(i)}}})
+		require.NoError(t, err)
+		saved = append(saved, id)
+	}
+	assert.Equal(t, []string{"20250101T100000Z", "20250101T100000Z-2", "20250101T100000Z-3"}, saved[:3])
+	assert.Equal(t, "20250101T100000Z-11", saved[10])
+
+	// snapshots of the same second are listed in the order they were saved
+	require.NoError(t, os.WriteFile(filepath.Join(store.dir, "audit-20250101T100000Z-x.json"), []byte("{}"), 0o600))
+	ids, err := store.List()
+	require.NoError(t, err)
+	assert.Equal(t, saved, ids)
+
+	snapshot, err := store.Load(saved[10])
+	require.NoError(t, err)
+	assert.Equal(t, "10", snapshot.Accounts[0].AccountId)
+	assert.Equal(t, saved[10], snapshot.ID())
+}
+
+func TestSnapshotStore_ListMissingDirectory(t *testing.T) {
+	ids, err := NewSnapshotStore(filepath.Join(t.TempDir(), "missing")).List()
+	require.NoError(t, err)
+	assert.Empty(t, ids)
+}
+
+func TestSnapshotStore_LoadErrors(t *testing.T) {
+	dir := t.TempDir()
+	store := NewSnapshotStore(dir)
+
+	_, err := store.Load("20250101T000000Z")
+	assert.ErrorIs(t, err, ErrSnapshotNotFound)
+
+	// IDs that are not snapshot IDs are not read, even if the file exists
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "audit-x.json"), []byte(`{"Version": 1}`), 0o600))
+	for _, id := range []string{"../../x", "../audit-20250101T000000Z", "x", "20250101T000000Z/../x"} {
+		_, err = store.Load(id)
+		assert.ErrorIs(t, err, ErrInvalidSnapshotId, id)
+	}
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "audit-20250101T000000Z.json"), []byte(`{"Version": 99}`), 0o600))
+	_, err = store.Load("20250101T000000Z")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unsupported version 99")
+}
+
+func TestSnapshotStore_AccountHistory(t *testing.T) {
+	store := NewSnapshotStore(t.TempDir())
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i, accounts := range [][]RootCredentials{
+		{{AccountId: "111111111111", LoginProfile: true}},
+		{{AccountId: "222222222222"}},
+		{{AccountId: "111111111111"}, {AccountId: "222222222222"}},
+	} {
+		_, err := store.Save(Snapshot{Timestamp: start.Add(time.Duration(i) * time.Hour), Accounts: accounts})
+		require.NoError(t, err)
+	}
+
+	history, err := store.AccountHistory("111111111111")
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+	assert.Equal(t, "20250101T000000Z", history[0].SnapshotId)
+	assert.True(t, history[0].Credentials.LoginProfile)
+	assert.Equal(t, "20250101T020000Z", history[1].SnapshotId)
+	assert.False(t, history[1].Credentials.LoginProfile)
+}
+
+func TestDiffSnapshots(t *testing.T) {
+	created := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	from := Snapshot{
+		Timestamp: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		Accounts: []RootCredentials{
+			{
+				AccountId:    "111111111111",
+				LoginProfile: true, LoginProfileCreateDate: created,
+				AccessKeys: []AccessKey{{AccessKeyId: "AKIAOLD", Status: "Active"}, {AccessKeyId: "AKIAKEEP", Status: "Active"}},
+				MfaDevices: []MFADevice{{SerialNumber: "GAHT1", EnableDate: created}},
+			},
+			{AccountId: "222222222222", Error: "access denied"},
+			{AccountId: "333333333333"},
+		},
+	}
+	to := Snapshot{
+		Timestamp: time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC),
+		Accounts: []RootCredentials{
+			{
+				AccountId:           "111111111111",
+				AccessKeys:          []AccessKey{{AccessKeyId: "AKIAKEEP", Status: "Inactive"}, {AccessKeyId: "AKIANEW", Status: "Active"}},
+				MfaDevices:          []MFADevice{{SerialNumber: "GAHT1", EnableDate: created}},
+				SigningCertificates: []SigningCertificate{{CertificateId: "CERT1", Status: "Active"}},
+			},
+			{AccountId: "222222222222"},
+			{AccountId: "444444444444"},
+		},
+	}
+
+	assert.Equal(t, []CredentialChange{
+		{AccountId: "111111111111", CredentialType: CheckLogin, Change: ChangeDisappeared},
+		{AccountId: "111111111111", CredentialType: CheckKeys, CredentialId: "AKIAOLD", Change: ChangeDisappeared},
+		{AccountId: "111111111111", CredentialType: CheckKeys, CredentialId: "AKIAKEEP", Change: ChangeModified, Details: "status Active -> Inactive"},
+		{AccountId: "111111111111", CredentialType: CheckKeys, CredentialId: "AKIANEW", Change: ChangeAppeared},
+		{AccountId: "111111111111", CredentialType: CheckCertificates, CredentialId: "CERT1", Change: ChangeAppeared},
+		{AccountId: "222222222222", Change: ChangeNotCompared, Details: "audit failed in snapshot 20250101T000000Z: access denied"},
+		{AccountId: "333333333333", Change: ChangeNotCompared, Details: "not in snapshot 20250102T000000Z"},
+		{AccountId: "444444444444", Change: ChangeNotCompared, Details: "not in snapshot 20250101T000000Z"},
+	}, DiffSnapshots(from, to))
+}
+
+func TestDiffSnapshots_FailedCheck(t *testing.T) {
+	from := Snapshot{Accounts: []RootCredentials{{AccountId: "111111111111", AccessKeys: []AccessKey{{AccessKeyId: "AKIA1"}}}}}
+	to := Snapshot{Accounts: []RootCredentials{{
+		AccountId: "111111111111",
+		Checks:    []CheckResult{{Check: CheckKeys, Success: false, Error: "throttled"}},
+	}}}
+
+	changes := DiffSnapshots(from, to)
+	require.Len(t, changes, 1)
+	assert.Equal(t, CheckKeys, changes[0].CredentialType)
+	assert.Equal(t, ChangeNotCompared, changes[0].Change)
+	assert.Contains(t, changes[0].Details, "throttled")
+}
@@ -1,5 +1,28 @@
 package rootmanager
 
+import (
+	"time"
+
+	internalaws "github.com/unicrons/aws-root-manager/internal/aws"
+)
+
+// MFA device types reported in MFADevice.Type.
+const (
+	MFADeviceTypeVirtual  = internalaws.MFADeviceTypeVirtual  // Virtual authenticator app
+	MFADeviceTypeHardware = internalaws.MFADeviceTypeHardware // Hardware TOTP token
+	MFADeviceTypeFIDO     = internalaws.MFADeviceTypeFIDO     // FIDO security key
+	MFADeviceTypeUnknown  = internalaws.MFADeviceTypeUnknown  // Type could not be derived from the serial number
+)
+
+// CredentialTypeAll matches every credential type, in an Exception or when
+// deleting credentials.
+const CredentialTypeAll = "all"
+
+// ValidAccountId reports whether accountId is a 12-digit AWS account ID.
+func ValidAccountId(accountId string) bool {
+	return internalaws.ValidAccountId(accountId)
+}
+
 // RootAccessStatus represents the status of centralized root access features in an AWS Organization.
 type RootAccessStatus struct {
 	TrustedAccess             bool // Whether AWS IAM has trusted access to the organization
@@ -9,19 +32,91 @@ type RootAccessStatus struct {
 
 // RootCredentials represents the root user credentials for an AWS account.
 type RootCredentials struct {
-	AccountId           string   // AWS account ID
-	LoginProfile        bool     // Whether a root password exists
-	AccessKeys          []string // List of root access key IDs
-	MfaDevices          []string // List of root MFA device serial numbers
-	SigningCertificates []string // List of root signing certificate IDs
-	Error               string   // Error message if audit failed for this account
+	AccountId              string               // AWS account ID
+	LoginProfile           bool                 // Whether a root password exists
+	LoginProfileCreateDate time.Time            // When the root password was created (zero if no login profile)
+	PasswordLastUsed       time.Time            // When the root password was last used to sign in (zero if never, or unknown)
+	PasswordLastUsedError  string               // Why the password usage could not be retrieved (empty if it was)
+	AccessKeys             []AccessKey          // List of root access keys
+	MfaDevices             []MFADevice          // List of root MFA devices
+	SigningCertificates    []SigningCertificate // List of root signing certificates
+	Checks                 []CheckResult        // Outcome of each audit check
+	Warnings               []string             // Details that could not be retrieved, such as when a credential was last used
+	Cancelled              bool                 // Whether the account was not audited because the operation was cancelled
+	Error                  string               // Error message if audit failed for this account
+}
+
+// CheckError returns the error message of the given audit check, or an empty
+// string if the check succeeded or was not run.
+func (c RootCredentials) CheckError(check string) string {
+	for _, result := range c.Checks {
+		if result.Check == check {
+			return result.Error
+		}
+	}
+	return ""
+}
+
+// Partial reports whether any audit check failed, leaving the account only partially audited.
+func (c RootCredentials) Partial() bool {
+	for _, result := range c.Checks {
+		if !result.Success {
+			return true
+		}
+	}
+	return false
+}
+
+// Audit checks reported in CheckResult.Check. They match the credential types
+// accepted by DeleteCredentials.
+const (
+	CheckLogin        = "login"
+	CheckKeys         = "keys"
+	CheckMFA          = "mfa"
+	CheckCertificates = "certificate"
+)
+
+// CheckResult represents the outcome of a single audit check for an account.
+type CheckResult struct {
+	Check   string // Check name (CheckLogin, CheckKeys, CheckMFA, CheckCertificates)
+	Success bool   // Whether the check completed
+	Error   string // Error message if the check failed (empty if Success=true)
+}
+
+// AccessKey represents a root access key and its usage metadata.
+type AccessKey struct {
+	AccessKeyId     string    // Access key ID
+	Status          string    // Key status (Active, Inactive)
+	CreateDate      time.Time // When the key was created
+	LastUsedDate    time.Time // When the key was last used (zero if never used, or unknown)
+	LastUsedService string    // Service the key was last used with
+	LastUsedRegion  string    // Region the key was last used in
+	LastUsedError   string    // Why the last usage could not be retrieved (empty if it was)
+}
+
+// MFADevice represents a root MFA device.
+type MFADevice struct {
+	SerialNumber string    // Device serial number or ARN
+	Type         string    // Device type (MFADeviceTypeVirtual, MFADeviceTypeHardware, MFADeviceTypeFIDO or MFADeviceTypeUnknown)
+	EnableDate   time.Time // When the device was enabled for the root user
+}
+
+// SigningCertificate represents a root signing certificate.
+type SigningCertificate struct {
+	CertificateId string    // Certificate ID
+	Status        string    // Certificate status (Active, Inactive)
+	UploadDate    time.Time // When the certificate was uploaded
 }
 
 // RecoveryResult represents the result of a root password recovery operation for an account.
 type RecoveryResult struct {
 	AccountId string // AWS account ID
 	Success   bool   // Whether recovery email was successfully sent
+	Cancelled bool   // Whether the account was not processed because the operation was cancelled
 	Error     string // Error message if recovery failed (empty if Success=true)
+
+	Verification      string // Verification status set by VerifyRecovery (empty if not verified)
+	VerificationError string // Why the verification did not pass (empty if verified)
 }
 
 // DeletionResult represents the result of a credential deletion operation for an account.
@@ -29,7 +124,27 @@ type DeletionResult struct {
 	AccountId      string // AWS account ID
 	CredentialType string // Type of credential deleted (login, keys, mfa, certificate, all)
 	Success        bool   // Whether deletion was successful
+	Cancelled      bool   // Whether the account was not processed because the operation was cancelled
 	Error          string // Error message if deletion failed (empty if Success=true)
+
+	Items []CredentialItemResult // Outcome for every credential the deletion attempted (empty if none was attempted)
+}
+
+// CredentialItemResult represents the result of deleting a single root credential.
+type CredentialItemResult struct {
+	CredentialType string // Credential type (CheckLogin, CheckKeys, CheckMFA or CheckCertificates)
+	CredentialId   string // Access key ID, MFA device serial number or signing certificate ID (empty for the login profile)
+	Success        bool   // Whether the credential was deleted (or the MFA device deactivated)
+	Error          string // Error message if deletion failed (empty if Success=true)
+
+	Verification      string // Verification status set by VerifyDeletion (empty if not verified)
+	VerificationError string // Why the verification did not pass (empty if verified)
+}
+
+// DeletionRequest lists the root credentials to delete in an account.
+type DeletionRequest struct {
+	AccountId string           // AWS account ID
+	Items     []CredentialItem // Credentials to delete
 }
 
 // PolicyDeletionResult represents the result of a resource policy deletion operation.
@@ -0,0 +1,93 @@
+package rootmanager
+
+import (
+	"fmt"
+	"slices"
+)
+
+// Verification statuses reported in CredentialItemResult.Verification and
+// RecoveryResult.Verification.
+const (
+	VerificationPassed  = "verified" // A new audit confirmed the change
+	VerificationFailed  = "failed"   // A new audit found the change did not take effect
+	VerificationUnknown = "unknown"  // The new audit of the account or of the credential type failed
+)
+
+// VerifyDeletion checks, against creds, a new audit of the accounts made after
+// the deletion, that every credential reported as deleted in results is gone.
+// Returns a copy of results with the Verification of those credentials set;
+// credentials that failed to be deleted are not verified.
+func VerifyDeletion(results []DeletionResult, creds []RootCredentials) []DeletionResult {
+	verified := slices.Clone(results)
+	for i, result := range verified {
+		verified[i].Items = slices.Clone(result.Items)
+		for j, item := range verified[i].Items {
+			if !item.Success {
+				continue
+			}
+			credential := CredentialItem{CredentialType: item.CredentialType, CredentialId: item.CredentialId}
+			verified[i].Items[j].Verification, verified[i].Items[j].VerificationError = verifyAccount(creds, result.AccountId, item.CredentialType,
+				func(accountCreds RootCredentials) string {
+					if credentialPresent(accountCreds, credential) {
+						return fmt.Sprintf("%s still present after deletion", credential)
+					}
+					return ""
+				})
+		}
+	}
+	return verified
+}
+
+// VerifyRecovery checks, against creds, a new audit of the accounts made after
+// the recovery, that every account reported as recovered in results now has a
+// root login profile. Returns a copy of results with the Verification of those
+// accounts set; other accounts are not verified.
+func VerifyRecovery(results []RecoveryResult, creds []RootCredentials) []RecoveryResult {
+	verified := slices.Clone(results)
+	for i, result := range verified {
+		if !result.Success {
+			continue
+		}
+		verified[i].Verification, verified[i].VerificationError = verifyAccount(creds, result.AccountId, CheckLogin,
+			func(accountCreds RootCredentials) string {
+				if !accountCreds.LoginProfile {
+					return "login profile missing after recovery"
+				}
+				return ""
+			})
+	}
+	return verified
+}
+
+// verifyAccount returns the verification status of a change to the check of the
+// account, and why it did not pass. failure returns why the audited account does
+// not reflect the change, or an empty string if it does.
+func verifyAccount(creds []RootCredentials, accountId, check string, failure func(RootCredentials) string) (string, string) {
+	idx := slices.IndexFunc(creds, func(c RootCredentials) bool { return c.AccountId == accountId })
+	if idx < 0 {
+		return VerificationUnknown, "account missing from the verification audit"
+	}
+	if err := checkCredentialsAudited(creds[idx], check); err != nil {
+		return VerificationUnknown, err.Error()
+	}
+	if reason := failure(creds[idx]); reason != "" {
+		return VerificationFailed, reason
+	}
+	return VerificationPassed, ""
+}
+
+// credentialPresent reports whether the audited account still has the credential.
+func credentialPresent(creds RootCredentials, item CredentialItem) bool {
+	switch item.CredentialType {
+	case CheckLogin:
+		return creds.LoginProfile
+	case CheckKeys:
+		return slices.Contains(accessKeyIds(creds.AccessKeys), item.CredentialId)
+	case CheckMFA:
+		return slices.Contains(mfaSerialNumbers(creds.MfaDevices), item.CredentialId)
+	case CheckCertificates:
+		return slices.Contains(certificateIds(creds.SigningCertificates), item.CredentialId)
+	default:
+		return false
+	}
+}
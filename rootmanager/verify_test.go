@@ -0,0 +1,71 @@
+package rootmanager
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyDeletion(t *testing.T) {
+	results := []DeletionResult{
+		{AccountId: "123456789012", CredentialType: "all", Items: []CredentialItemResult{
+			{CredentialType: CheckLogin, Success: true},
+			{CredentialType: CheckKeys, CredentialId: "AKIA1", Success: true},
+			{CredentialType: CheckKeys, CredentialId: "AKIA2", Error: "access denied"},
+			{CredentialType: CheckMFA, CredentialId: "mfa-1", Success: true},
+		}},
+		{AccountId: "234567890123", CredentialType: "keys", Success: true, Items: []CredentialItemResult{
+			{CredentialType: CheckKeys, CredentialId: "AKIA3", Success: true},
+		}},
+		{AccountId: "345678901234", CredentialType: "keys", Success: true, Items: []CredentialItemResult{
+			{CredentialType: CheckKeys, CredentialId: "AKIA4", Success: true},
+		}},
+	}
+	creds := []RootCredentials{
+		{
+			AccountId:  "123456789012",
+			AccessKeys: []AccessKey{{AccessKeyId: "AKIA1"}, {AccessKeyId: "AKIA2"}},
+			Checks:     []CheckResult{{Check: CheckMFA, Error: "throttled"}},
+		},
+		{AccountId: "234567890123"},
+	}
+
+	verified := VerifyDeletion(results, creds)
+	require.Len(t, verified, 3)
+	assert.Equal(t, []CredentialItemResult{
+		{CredentialType: CheckLogin, Success: true, Verification: VerificationPassed},
+		{CredentialType: CheckKeys, CredentialId: "AKIA1", Success: true, Verification: VerificationFailed, VerificationError: "keys AKIA1 still present after deletion"},
+		{CredentialType: CheckKeys, CredentialId: "AKIA2", Error: "access denied"},
+		{CredentialType: CheckMFA, CredentialId: "mfa-1", Success: true, Verification: VerificationUnknown, VerificationError: "mfa audit check failed: throttled"},
+	}, verified[0].Items)
+	assert.Equal(t, VerificationPassed, verified[1].Items[0].Verification)
+	assert.Equal(t, VerificationUnknown, verified[2].Items[0].Verification)
+	assert.Equal(t, "account missing from the verification audit", verified[2].Items[0].VerificationError)
+
+	// the given results are left unchanged
+	assert.Empty(t, results[0].Items[0].Verification)
+}
+
+func TestVerifyRecovery(t *testing.T) {
+	results := []RecoveryResult{
+		{AccountId: "123456789012", Success: true},
+		{AccountId: "234567890123", Success: true},
+		{AccountId: "345678901234", Success: true},
+		{AccountId: "456789012345", Error: "access denied"},
+	}
+	creds := []RootCredentials{
+		{AccountId: "123456789012", LoginProfile: true},
+		{AccountId: "234567890123"},
+		{AccountId: "345678901234", Error: "access denied"},
+		{AccountId: "456789012345"},
+	}
+
+	verified := VerifyRecovery(results, creds)
+	assert.Equal(t, VerificationPassed, verified[0].Verification)
+	assert.Equal(t, VerificationFailed, verified[1].Verification)
+	assert.Equal(t, "login profile missing after recovery", verified[1].VerificationError)
+	assert.Equal(t, VerificationUnknown, verified[2].Verification)
+	assert.Equal(t, "account audit failed: access denied", verified[2].VerificationError)
+	assert.Empty(t, verified[3].Verification)
+}